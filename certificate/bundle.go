@@ -0,0 +1,176 @@
+package certificate
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/rand"
+	"crypto/x509"
+	"encoding/pem"
+	"fmt"
+	"strings"
+
+	"software.sslmate.com/src/go-pkcs12"
+)
+
+// intermediateTypes are the types of intermediate certificates, see
+// GetIntermediateCertificate
+var intermediateTypes = []string{"cert_std", "cert_free", "cert_bus", "cert_pro"}
+
+// IntermediateType returns the type of the intermediate certificate
+// of a package, such as "cert_std" for the "cert_std_1_0_0" package
+func IntermediateType(pkg Package) (string, error) {
+	for _, typ := range intermediateTypes {
+		if pkg.Name == typ || strings.HasPrefix(pkg.Name, typ+"_") {
+			return typ, nil
+		}
+	}
+	if pkg.Type != "" {
+		typ := "cert_" + strings.TrimPrefix(pkg.Type, "cert_")
+		for _, known := range intermediateTypes {
+			if typ == known {
+				return typ, nil
+			}
+		}
+	}
+	return "", fmt.Errorf("No intermediate certificate known for the package '%s'", pkg.Name)
+}
+
+// Bundle is an issued certificate with the intermediate certificates
+// chaining it to a root certificate authority
+type Bundle struct {
+	Certificate   *x509.Certificate
+	Intermediates []*x509.Certificate
+}
+
+// NewBundle parses the PEM or DER encoded certificate and the PEM
+// encoded intermediate certificates
+func NewBundle(certificate, intermediates []byte) (*Bundle, error) {
+	certs, err := parseCertificates(certificate)
+	if err != nil {
+		return nil, err
+	}
+	bundle := &Bundle{Certificate: certs[0], Intermediates: certs[1:]}
+	if len(bytes.TrimSpace(intermediates)) > 0 {
+		chain, err := parseCertificates(intermediates)
+		if err != nil {
+			return nil, err
+		}
+		bundle.Intermediates = append(bundle.Intermediates, chain...)
+	}
+	return bundle, nil
+}
+
+func parseCertificates(data []byte) ([]*x509.Certificate, error) {
+	if !bytes.Contains(data, []byte("-----BEGIN")) {
+		return x509.ParseCertificates(data)
+	}
+	var certs []*x509.Certificate
+	for {
+		var block *pem.Block
+		block, data = pem.Decode(data)
+		if block == nil {
+			break
+		}
+		if block.Type != "CERTIFICATE" {
+			continue
+		}
+		cert, err := x509.ParseCertificate(block.Bytes)
+		if err != nil {
+			return nil, err
+		}
+		certs = append(certs, cert)
+	}
+	if len(certs) == 0 {
+		return nil, fmt.Errorf("No certificate found in the PEM data")
+	}
+	return certs, nil
+}
+
+// Verify checks that the certificate chains to one of the roots
+// through the intermediates, and is currently valid. The system roots
+// are used when roots is nil.
+func (b *Bundle) Verify(roots *x509.CertPool) error {
+	pool := x509.NewCertPool()
+	for _, cert := range b.Intermediates {
+		pool.AddCert(cert)
+	}
+	_, err := b.Certificate.Verify(x509.VerifyOptions{
+		Roots:         roots,
+		Intermediates: pool,
+		KeyUsages:     []x509.ExtKeyUsage{x509.ExtKeyUsageAny},
+	})
+	return err
+}
+
+// FullChain returns the PEM encoded certificate followed by the
+// intermediates, as expected by most web servers
+func (b *Bundle) FullChain() []byte {
+	var buf bytes.Buffer
+	for _, cert := range append([]*x509.Certificate{b.Certificate}, b.Intermediates...) {
+		_ = pem.Encode(&buf, &pem.Block{Type: "CERTIFICATE", Bytes: cert.Raw})
+	}
+	return buf.Bytes()
+}
+
+// PKCS12 returns a PKCS #12 archive of the private key of the
+// certificate and the chain, protected by the password
+func (b *Bundle) PKCS12(key crypto.Signer, password string) ([]byte, error) {
+	if err := b.checkKey(key); err != nil {
+		return nil, err
+	}
+	return pkcs12.Encode(rand.Reader, key, b.Certificate, b.Intermediates, password)
+}
+
+// checkKey verifies that the private key matches the certificate
+func (b *Bundle) checkKey(key crypto.Signer) error {
+	public, err := x509.MarshalPKIXPublicKey(key.Public())
+	if err != nil {
+		return err
+	}
+	if !bytes.Equal(public, b.Certificate.RawSubjectPublicKeyInfo) {
+		return fmt.Errorf("The private key does not match the certificate")
+	}
+	return nil
+}
+
+// GetCertificateBundle returns an issued certificate with the
+// intermediate certificate of its package, after checking that the
+// chain is valid with the system roots
+func (g *Certificate) GetCertificateBundle(certificateId string) (*Bundle, error) {
+	bundle, err := g.GetCertificateBundleUnverified(certificateId)
+	if err != nil {
+		return nil, err
+	}
+	if err := bundle.Verify(nil); err != nil {
+		return nil, fmt.Errorf("Invalid certificate chain (error '%w')", err)
+	}
+	return bundle, nil
+}
+
+// GetCertificateBundleUnverified returns an issued certificate with
+// the intermediate certificate of its package, without checking the
+// chain. It is meant for the hosts whose system roots are missing or
+// outdated, or to bundle an expired certificate; Verify can check the
+// chain with other roots.
+func (g *Certificate) GetCertificateBundleUnverified(certificateId string) (*Bundle, error) {
+	cert, err := g.GetCertificate(certificateId)
+	if err != nil {
+		return nil, err
+	}
+	if cert.Package == nil {
+		return nil, fmt.Errorf("The certificate %s has no package", certificateId)
+	}
+	typ, err := IntermediateType(*cert.Package)
+	if err != nil {
+		return nil, err
+	}
+	data, err := g.GetCertificateData(certificateId)
+	if err != nil {
+		return nil, err
+	}
+	intermediate, err := g.GetIntermediateCertificate(typ)
+	if err != nil {
+		return nil, err
+	}
+	return NewBundle(data, intermediate)
+}
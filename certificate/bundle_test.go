@@ -0,0 +1,214 @@
+package certificate_test
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/rand"
+	"crypto/sha1"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/binary"
+	"encoding/pem"
+	"errors"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/go-gandi/go-gandi/certificate"
+	"github.com/go-gandi/go-gandi/config"
+	"gopkg.in/h2non/gock.v1"
+	"software.sslmate.com/src/go-pkcs12"
+)
+
+type testChain struct {
+	root, intermediate, leaf []byte
+	key                      crypto.Signer
+	roots                    *x509.CertPool
+}
+
+func issue(t *testing.T, template, parent *x509.Certificate, key crypto.Signer, parentKey crypto.Signer) (*x509.Certificate, []byte) {
+	der, err := x509.CreateCertificate(rand.Reader, template, parent, key.Public(), parentKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return cert, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})
+}
+
+func newChain(t *testing.T) testChain {
+	var keys []crypto.Signer
+	for i := 0; i < 3; i++ {
+		key, err := certificate.GenerateKey(certificate.KeyECDSA, 0)
+		if err != nil {
+			t.Fatal(err)
+		}
+		keys = append(keys, key)
+	}
+	now := time.Now()
+	ca := func(serial int64, name string) *x509.Certificate {
+		return &x509.Certificate{
+			SerialNumber:          big.NewInt(serial),
+			Subject:               pkix.Name{CommonName: name},
+			NotBefore:             now.Add(-time.Hour),
+			NotAfter:              now.Add(time.Hour),
+			IsCA:                  true,
+			BasicConstraintsValid: true,
+			KeyUsage:              x509.KeyUsageCertSign,
+		}
+	}
+	root, rootPEM := issue(t, ca(1, "Test Root"), ca(1, "Test Root"), keys[0], keys[0])
+	intermediate, intermediatePEM := issue(t, ca(2, "Test Intermediate"), root, keys[1], keys[0])
+	_, leafPEM := issue(t, &x509.Certificate{
+		SerialNumber: big.NewInt(3),
+		Subject:      pkix.Name{CommonName: "www.example.com"},
+		DNSNames:     []string{"www.example.com"},
+		NotBefore:    now.Add(-time.Hour),
+		NotAfter:     now.Add(time.Hour),
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+	}, intermediate, keys[2], keys[1])
+	roots := x509.NewCertPool()
+	roots.AddCert(root)
+	return testChain{root: rootPEM, intermediate: intermediatePEM, leaf: leafPEM, key: keys[2], roots: roots}
+}
+
+func TestIntermediateType(t *testing.T) {
+	cases := map[string]string{"cert_std_1_0_0": "cert_std", "cert_bus_3_1_0": "cert_bus", "cert_pro": "cert_pro"}
+	for name, expected := range cases {
+		typ, err := certificate.IntermediateType(certificate.Package{Name: name})
+		if err != nil || typ != expected {
+			t.Errorf("Expected %s for %s, got %s (%v)", expected, name, typ, err)
+		}
+	}
+	if _, err := certificate.IntermediateType(certificate.Package{Name: "cert_unknown"}); err == nil {
+		t.Error("An unknown package should be rejected")
+	}
+}
+
+func TestBundle(t *testing.T) {
+	chain := newChain(t)
+	bundle, err := certificate.NewBundle(chain.leaf, chain.intermediate)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := bundle.Verify(chain.roots); err != nil {
+		t.Fatal(err)
+	}
+	if err := bundle.Verify(x509.NewCertPool()); err == nil {
+		t.Fatal("The chain should not be valid without its root")
+	}
+	if !bytes.Equal(bundle.FullChain(), append(chain.leaf, chain.intermediate...)) {
+		t.Errorf("Unexpected full chain\n%s", bundle.FullChain())
+	}
+
+	other, _ := certificate.GenerateKey(certificate.KeyECDSA, 0)
+	if _, err := bundle.PKCS12(other, "password"); err == nil {
+		t.Error("A key not matching the certificate should be rejected")
+	}
+	archive, err := bundle.PKCS12(chain.key, "password")
+	if err != nil {
+		t.Fatal(err)
+	}
+	key, cert, cas, err := pkcs12.DecodeChain(archive, "password")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !chain.key.(*ecdsa.PrivateKey).Equal(key) || !cert.Equal(bundle.Certificate) || len(cas) != 1 {
+		t.Error("Unexpected PKCS #12 content")
+	}
+}
+
+func TestJKS(t *testing.T) {
+	chain := newChain(t)
+	bundle, err := certificate.NewBundle(chain.leaf, chain.intermediate)
+	if err != nil {
+		t.Fatal(err)
+	}
+	store, err := bundle.JKS(chain.key, "server", "changeit")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Check the header and the integrity digest as keytool does
+	pass := []byte{0, 'c', 0, 'h', 0, 'a', 0, 'n', 0, 'g', 0, 'e', 0, 'i', 0, 't'}
+	data, digest := store[:len(store)-sha1.Size], store[len(store)-sha1.Size:]
+	h := sha1.New()
+	h.Write(pass)
+	h.Write([]byte("Mighty Aphrodite"))
+	h.Write(data)
+	if !bytes.Equal(h.Sum(nil), digest) {
+		t.Fatal("Invalid keystore digest")
+	}
+	var header struct {
+		Magic, Version, Count, Tag uint32
+		AliasLength                uint16
+	}
+	if err := binary.Read(bytes.NewReader(data), binary.BigEndian, &header); err != nil {
+		t.Fatal(err)
+	}
+	if header.Magic != 0xfeedfeed || header.Version != 2 || header.Count != 1 || header.Tag != 1 || header.AliasLength != 6 {
+		t.Fatalf("Unexpected keystore header %+v", header)
+	}
+	if !bytes.Contains(data, bundle.Intermediates[0].Raw) {
+		t.Fatal("The keystore does not contain the intermediate certificate")
+	}
+}
+
+func TestGetCertificateBundle(t *testing.T) {
+	defer gock.Off()
+	chain := newChain(t)
+	gock.New("https://api.gandi.net/v5/").
+		Get("certificate/issued-certs/cert-id").
+		Reply(200).
+		JSON(certificate.CertificateType{ID: "cert-id", Package: &certificate.Package{Name: "cert_std_1_0_0"}})
+	gock.New("https://api.gandi.net/v5/").
+		Get("certificate/issued-certs/cert-id/crt").
+		Reply(200).
+		BodyString(string(chain.leaf))
+	gock.New("https://api.gandi.net/v5/").
+		Get("certificate/pem/cert_std").
+		Reply(200).
+		BodyString(string(chain.intermediate))
+
+	// The test root is not trusted by the system
+	_, err := certificate.New(config.Config{}).GetCertificateBundle("cert-id")
+	var unknown x509.UnknownAuthorityError
+	if !errors.As(err, &unknown) {
+		t.Fatalf("Expected an unknown authority error, got %v", err)
+	}
+	if !gock.IsDone() {
+		t.Fatal("All the expected requests have not been sent")
+	}
+}
+
+func TestGetCertificateBundleUnverified(t *testing.T) {
+	defer gock.Off()
+	chain := newChain(t)
+	gock.New("https://api.gandi.net/v5/").
+		Get("certificate/issued-certs/cert-id").
+		Reply(200).
+		JSON(certificate.CertificateType{ID: "cert-id", Package: &certificate.Package{Name: "cert_std_1_0_0"}})
+	gock.New("https://api.gandi.net/v5/").
+		Get("certificate/issued-certs/cert-id/crt").
+		Reply(200).
+		BodyString(string(chain.leaf))
+	gock.New("https://api.gandi.net/v5/").
+		Get("certificate/pem/cert_std").
+		Reply(200).
+		BodyString(string(chain.intermediate))
+
+	bundle, err := certificate.New(config.Config{}).GetCertificateBundleUnverified("cert-id")
+	if err != nil {
+		t.Fatal(err)
+	}
+	// The chain can still be checked with other roots
+	if err := bundle.Verify(chain.roots); err != nil {
+		t.Fatal(err)
+	}
+	if !gock.IsDone() {
+		t.Fatal("All the expected requests have not been sent")
+	}
+}
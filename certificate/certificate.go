@@ -2,6 +2,7 @@ package certificate
 
 import (
 	"encoding/json"
+	"fmt"
 
 	"github.com/go-gandi/go-gandi/config"
 	"github.com/go-gandi/go-gandi/internal/client"
@@ -43,12 +44,76 @@ func (g *Certificate) GetCertificateData(certificateId string) (data []byte, err
 	return
 }
 
-// CreateCertificate creates a certificate
+// CreateCertificate orders a certificate. The domain control
+// validation starts once it is created, see GetDCVParams.
 func (g *Certificate) CreateCertificate(req CreateCertificateRequest) (response CreateCertificateResponse, err error) {
+	switch {
+	case req.Package == "":
+		return response, fmt.Errorf("The certificate package is required")
+	case req.CN == "" && req.CSR == "":
+		return response, fmt.Errorf("The CN or the CSR of the certificate is required")
+	case req.DCVMethod != "" && !req.DCVMethod.valid():
+		return response, fmt.Errorf("Unknown domain control validation method '%s'", req.DCVMethod)
+	case req.Duration < 0:
+		return response, fmt.Errorf("The certificate duration must be positive")
+	}
 	_, err = g.client.Post("issued-certs", req, &response)
 	return
 }
 
+// GetDCVParams returns the parameters of the domain control
+// validation of a certificate for a validation method, such as the
+// DNS records to create with DCVDNS
+func (g *Certificate) GetDCVParams(certificateId string, method DCVMethod) (params DCVParams, err error) {
+	if !method.valid() {
+		return params, fmt.Errorf("Unknown domain control validation method '%s'", method)
+	}
+	_, err = g.client.Post("issued-certs/"+certificateId+"/dcv_params", dcvRequest{DCVMethod: method}, &params)
+	return
+}
+
+// ResendDCV asks the certificate authority to check the domain
+// control validation again, or to resend the validation email
+func (g *Certificate) ResendDCV(certificateId string) (response ErrorResponse, err error) {
+	_, err = g.client.Put("issued-certs/"+certificateId+"/dcv", nil, &response)
+	return
+}
+
+// UpdateDCV changes the domain control validation method of a pending
+// certificate
+func (g *Certificate) UpdateDCV(certificateId string, method DCVMethod) (response ErrorResponse, err error) {
+	if !method.valid() {
+		return response, fmt.Errorf("Unknown domain control validation method '%s'", method)
+	}
+	_, err = g.client.Patch("issued-certs/"+certificateId, dcvRequest{DCVMethod: method}, &response)
+	return
+}
+
+// RenewCertificate orders the renewal of a certificate. The domain
+// control validation of the renewed certificate starts as for a new
+// order.
+func (g *Certificate) RenewCertificate(certificateId string, req RenewCertificateRequest) (response CreateCertificateResponse, err error) {
+	switch {
+	case req.DCVMethod != "" && !req.DCVMethod.valid():
+		return response, fmt.Errorf("Unknown domain control validation method '%s'", req.DCVMethod)
+	case req.Duration < 0:
+		return response, fmt.Errorf("The certificate duration must be positive")
+	}
+	_, err = g.client.Post("issued-certs/"+certificateId, req, &response)
+	return
+}
+
+// ReissueCertificate reissues a certificate for the key of a new CSR,
+// for instance after the previous key was compromised. The names of
+// the CSR must be the ones of the certificate.
+func (g *Certificate) ReissueCertificate(certificateId string, csr string) (response ErrorResponse, err error) {
+	if csr == "" {
+		return response, fmt.Errorf("The CSR of the certificate is required")
+	}
+	_, err = g.client.Put("issued-certs/"+certificateId, reissueRequest{CSR: csr}, &response)
+	return
+}
+
 // DeleteCertificate revokes a certificate
 func (g *Certificate) DeleteCertificate(certificateId string) (response ErrorResponse, err error) {
 	_, err = g.client.Delete("issued-certs/"+certificateId, nil, &response)
@@ -0,0 +1,187 @@
+package certificate_test
+
+import (
+	"testing"
+
+	"github.com/go-gandi/go-gandi/certificate"
+	"github.com/go-gandi/go-gandi/config"
+	"gopkg.in/h2non/gock.v1"
+)
+
+func TestGetDCVParams(t *testing.T) {
+	defer gock.Off()
+	gock.New("https://api.gandi.net/v5/").
+		Post("certificate/issued-certs/cert-id/dcv_params").
+		JSON(map[string]string{"dcv_method": "dns"}).
+		Reply(200).
+		JSON(certificate.DCVParams{
+			DCVMethod:  certificate.DCVDNS,
+			DNSRecords: []string{"_0123abcd.www.example.com. 10800 IN CNAME 4567.89ab.sectigo.com."},
+		})
+
+	params, err := certificate.New(config.Config{}).GetDCVParams("cert-id", certificate.DCVDNS)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if params.DCVMethod != certificate.DCVDNS || len(params.DNSRecords) != 1 {
+		t.Fatalf("Unexpected parameters %+v", params)
+	}
+	if !gock.IsDone() {
+		t.Fatal("All the expected requests have not been sent")
+	}
+	// No request is sent for an unknown method
+	if _, err := certificate.New(config.Config{}).GetDCVParams("cert-id", "ftp"); err == nil {
+		t.Fatal("An unknown validation method should be rejected")
+	}
+}
+
+func TestResendDCV(t *testing.T) {
+	defer gock.Off()
+	gock.New("https://api.gandi.net/v5/").
+		Put("certificate/issued-certs/cert-id/dcv").
+		Reply(200).
+		JSON(map[string]string{"message": "The validation has been restarted"})
+
+	if _, err := certificate.New(config.Config{}).ResendDCV("cert-id"); err != nil {
+		t.Fatal(err)
+	}
+	if !gock.IsDone() {
+		t.Fatal("All the expected requests have not been sent")
+	}
+}
+
+func TestUpdateDCV(t *testing.T) {
+	defer gock.Off()
+	gock.New("https://api.gandi.net/v5/").
+		Patch("certificate/issued-certs/cert-id").
+		JSON(map[string]string{"dcv_method": "email"}).
+		Reply(200).
+		JSON(map[string]string{"message": "The validation method has been updated"})
+
+	if _, err := certificate.New(config.Config{}).UpdateDCV("cert-id", certificate.DCVEmail); err != nil {
+		t.Fatal(err)
+	}
+	if !gock.IsDone() {
+		t.Fatal("All the expected requests have not been sent")
+	}
+}
+
+func TestCreateCertificate(t *testing.T) {
+	defer gock.Off()
+	gock.New("https://api.gandi.net/v5/").
+		Post("certificate/issued-certs").
+		JSON(map[string]interface{}{"cn": "www.example.com", "package": "cert_std_1_0_0", "dcv_method": "file", "duration": 2}).
+		Reply(202).
+		JSON(certificate.CreateCertificateResponse{ID: "cert-id"})
+
+	response, err := certificate.New(config.Config{}).CreateCertificate(certificate.CreateCertificateRequest{
+		CN:        "www.example.com",
+		Package:   "cert_std_1_0_0",
+		DCVMethod: certificate.DCVFile,
+		Duration:  2,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if response.ID != "cert-id" {
+		t.Fatalf("Expected the certificate cert-id, got %+v", response)
+	}
+	if !gock.IsDone() {
+		t.Fatal("All the expected requests have not been sent")
+	}
+}
+
+func TestCreateCertificateValidation(t *testing.T) {
+	defer gock.Off()
+	// The order would be accepted if it was sent
+	gock.New("https://api.gandi.net/v5/").
+		Post("certificate/issued-certs").
+		Reply(202).
+		JSON(certificate.CreateCertificateResponse{ID: "cert-id"})
+	tests := []struct {
+		name string
+		req  certificate.CreateCertificateRequest
+	}{
+		{"no package", certificate.CreateCertificateRequest{CN: "www.example.com"}},
+		{"no cn nor csr", certificate.CreateCertificateRequest{Package: "cert_std_1_0_0"}},
+		{"unknown method", certificate.CreateCertificateRequest{CN: "www.example.com", Package: "cert_std_1_0_0", DCVMethod: "ftp"}},
+		{"negative duration", certificate.CreateCertificateRequest{CN: "www.example.com", Package: "cert_std_1_0_0", Duration: -1}},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if _, err := certificate.New(config.Config{}).CreateCertificate(test.req); err == nil {
+				t.Fatalf("The order %+v should be rejected", test.req)
+			}
+		})
+	}
+	if !gock.IsPending() {
+		t.Fatal("No request should have been sent")
+	}
+}
+
+func TestRenewCertificate(t *testing.T) {
+	defer gock.Off()
+	gock.New("https://api.gandi.net/v5/").
+		Post("certificate/issued-certs/cert-id").
+		JSON(map[string]interface{}{"dcv_method": "dns", "duration": 1}).
+		Reply(202).
+		JSON(certificate.CreateCertificateResponse{ID: "renewed-id"})
+
+	response, err := certificate.New(config.Config{}).RenewCertificate("cert-id", certificate.RenewCertificateRequest{
+		DCVMethod: certificate.DCVDNS,
+		Duration:  1,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if response.ID != "renewed-id" {
+		t.Fatalf("Expected the certificate renewed-id, got %+v", response)
+	}
+	if !gock.IsDone() {
+		t.Fatal("All the expected requests have not been sent")
+	}
+}
+
+func TestReissueCertificate(t *testing.T) {
+	defer gock.Off()
+	csr := "-----BEGIN CERTIFICATE REQUEST-----\n"
+	gock.New("https://api.gandi.net/v5/").
+		Put("certificate/issued-certs/cert-id").
+		JSON(map[string]string{"csr": csr}).
+		Reply(202).
+		JSON(map[string]string{"message": "The certificate is being reissued"})
+
+	if _, err := certificate.New(config.Config{}).ReissueCertificate("cert-id", csr); err != nil {
+		t.Fatal(err)
+	}
+	if !gock.IsDone() {
+		t.Fatal("All the expected requests have not been sent")
+	}
+}
+
+func TestRenewAndReissueValidation(t *testing.T) {
+	defer gock.Off()
+	// The requests would be accepted if they were sent
+	gock.New("https://api.gandi.net/v5/").
+		Post("certificate/issued-certs/cert-id").
+		Reply(202).
+		JSON(certificate.CreateCertificateResponse{ID: "renewed-id"})
+	gock.New("https://api.gandi.net/v5/").
+		Put("certificate/issued-certs/cert-id").
+		Reply(202).
+		JSON(map[string]string{"message": "The certificate is being reissued"})
+
+	c := certificate.New(config.Config{})
+	if _, err := c.RenewCertificate("cert-id", certificate.RenewCertificateRequest{DCVMethod: "ftp"}); err == nil {
+		t.Error("An unknown validation method should be rejected")
+	}
+	if _, err := c.RenewCertificate("cert-id", certificate.RenewCertificateRequest{Duration: -1}); err == nil {
+		t.Error("A negative duration should be rejected")
+	}
+	if _, err := c.ReissueCertificate("cert-id", ""); err == nil {
+		t.Error("A reissue without CSR should be rejected")
+	}
+	if len(gock.Pending()) != 2 {
+		t.Fatal("No request should have been sent")
+	}
+}
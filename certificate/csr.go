@@ -0,0 +1,215 @@
+package certificate
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"fmt"
+	"os"
+	"strings"
+
+	"golang.org/x/net/idna"
+)
+
+// KeyType is the algorithm of a private key
+type KeyType string
+
+const (
+	// KeyRSA keys are 2048 bits long by default
+	KeyRSA KeyType = "rsa"
+	// KeyECDSA keys use the P-256 curve by default
+	KeyECDSA KeyType = "ecdsa"
+)
+
+// GenerateKey generates a private key of the type. The size is the
+// number of bits of RSA keys, at least 2048, or the size of the ECDSA
+// curve among 256, 384 and 521. Zero selects the default size.
+func GenerateKey(keyType KeyType, size int) (crypto.Signer, error) {
+	switch keyType {
+	case KeyRSA:
+		if size == 0 {
+			size = 2048
+		}
+		if size < 2048 {
+			return nil, fmt.Errorf("RSA keys must have at least 2048 bits")
+		}
+		return rsa.GenerateKey(rand.Reader, size)
+	case KeyECDSA:
+		var curve elliptic.Curve
+		switch size {
+		case 0, 256:
+			curve = elliptic.P256()
+		case 384:
+			curve = elliptic.P384()
+		case 521:
+			curve = elliptic.P521()
+		default:
+			return nil, fmt.Errorf("Unsupported ECDSA curve size %d", size)
+		}
+		return ecdsa.GenerateKey(curve, rand.Reader)
+	}
+	return nil, fmt.Errorf("Unknown key type '%s'", keyType)
+}
+
+// EncodePrivateKey returns the key as a PEM encoded PKCS #8 block
+func EncodePrivateKey(key crypto.Signer) ([]byte, error) {
+	der, err := x509.MarshalPKCS8PrivateKey(key)
+	if err != nil {
+		return nil, err
+	}
+	return pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der}), nil
+}
+
+// DecodePrivateKey parses a PEM encoded private key, in the PKCS #8,
+// PKCS #1 or SEC 1 formats. The passphrase decrypts the keys written
+// by EncodeEncryptedPrivateKey.
+func DecodePrivateKey(data, passphrase []byte) (crypto.Signer, error) {
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, fmt.Errorf("No PEM data found")
+	}
+	var key interface{}
+	var err error
+	switch block.Type {
+	case "ENCRYPTED PRIVATE KEY":
+		if len(passphrase) == 0 {
+			return nil, fmt.Errorf("The private key is encrypted and requires a passphrase")
+		}
+		var der []byte
+		if der, err = decryptPKCS8(block.Bytes, passphrase); err == nil {
+			key, err = x509.ParsePKCS8PrivateKey(der)
+		}
+	case "PRIVATE KEY":
+		key, err = x509.ParsePKCS8PrivateKey(block.Bytes)
+	case "RSA PRIVATE KEY":
+		key, err = x509.ParsePKCS1PrivateKey(block.Bytes)
+	case "EC PRIVATE KEY":
+		key, err = x509.ParseECPrivateKey(block.Bytes)
+	default:
+		return nil, fmt.Errorf("Unexpected PEM block '%s'", block.Type)
+	}
+	if err != nil {
+		return nil, err
+	}
+	signer, ok := key.(crypto.Signer)
+	if !ok {
+		return nil, fmt.Errorf("Unsupported private key type %T", key)
+	}
+	return signer, nil
+}
+
+// WritePrivateKey writes the PEM encoded key to a new file only
+// readable by its owner. It fails if the file already exists, so that
+// a key in use is never overwritten. The key is encrypted when a
+// passphrase is given.
+func WritePrivateKey(path string, key crypto.Signer, passphrase []byte) error {
+	var data []byte
+	var err error
+	if len(passphrase) > 0 {
+		data, err = EncodeEncryptedPrivateKey(key, passphrase)
+	} else {
+		data, err = EncodePrivateKey(key)
+	}
+	if err != nil {
+		return err
+	}
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0600)
+	if err != nil {
+		return err
+	}
+	if _, err := f.Write(data); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// CSROptions are the names of a certificate signing request
+type CSROptions struct {
+	CN string
+	// AltNames are added to the CN in the subject alternative names
+	AltNames           []string
+	Organization       string
+	OrganizationalUnit string
+	Locality           string
+	Province           string
+	Country            string
+}
+
+// CSROptionsFor returns the options of the CSR of a certificate,
+// using its Unicode names when they are set
+func CSROptionsFor(cert CertificateType) CSROptions {
+	opts := CSROptions{CN: cert.CN, AltNames: cert.AltNames}
+	if cert.CNUnicode != "" {
+		opts.CN = cert.CNUnicode
+	}
+	if len(cert.AltNamesUnicode) > 0 {
+		opts.AltNames = cert.AltNamesUnicode
+	}
+	return opts
+}
+
+// ToASCII converts an internationalized domain name to its punycode
+// form. A leading wildcard label is kept.
+func ToASCII(name string) (string, error) {
+	wildcard := strings.HasPrefix(name, "*.")
+	name = strings.TrimPrefix(name, "*.")
+	ascii, err := idna.Lookup.ToASCII(name)
+	if err != nil {
+		return "", fmt.Errorf("Invalid domain name '%s' (error '%w')", name, err)
+	}
+	if wildcard {
+		ascii = "*." + ascii
+	}
+	return ascii, nil
+}
+
+// CreateCSR returns a PEM encoded certificate signing request signed
+// with the key. Internationalized names are converted to punycode,
+// and the CN is also a subject alternative name.
+func CreateCSR(key crypto.Signer, opts CSROptions) ([]byte, error) {
+	if opts.CN == "" {
+		return nil, fmt.Errorf("The CN is required")
+	}
+	cn, err := ToASCII(opts.CN)
+	if err != nil {
+		return nil, err
+	}
+	names := []string{cn}
+	seen := map[string]bool{cn: true}
+	for _, name := range opts.AltNames {
+		ascii, err := ToASCII(name)
+		if err != nil {
+			return nil, err
+		}
+		if !seen[ascii] {
+			seen[ascii] = true
+			names = append(names, ascii)
+		}
+	}
+	subject := pkix.Name{CommonName: cn}
+	for field, value := range map[*[]string]string{
+		&subject.Organization:       opts.Organization,
+		&subject.OrganizationalUnit: opts.OrganizationalUnit,
+		&subject.Locality:           opts.Locality,
+		&subject.Province:           opts.Province,
+		&subject.Country:            opts.Country,
+	} {
+		if value != "" {
+			*field = []string{value}
+		}
+	}
+	der, err := x509.CreateCertificateRequest(rand.Reader, &x509.CertificateRequest{
+		Subject:  subject,
+		DNSNames: names,
+	}, key)
+	if err != nil {
+		return nil, err
+	}
+	return pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE REQUEST", Bytes: der}), nil
+}
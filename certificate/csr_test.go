@@ -0,0 +1,164 @@
+package certificate_test
+
+import (
+	"crypto/ecdsa"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/asn1"
+	"encoding/pem"
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/go-gandi/go-gandi/certificate"
+)
+
+func TestCreateCSR(t *testing.T) {
+	key, err := certificate.GenerateKey(certificate.KeyECDSA, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	opts := certificate.CSROptionsFor(certificate.CertificateType{
+		CN:              "xn--exmple-cua.com",
+		CNUnicode:       "exämple.com",
+		AltNamesUnicode: []string{"*.exämple.com", "exämple.com", "www.example.org"},
+	})
+	data, err := certificate.CreateCSR(key, opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	block, _ := pem.Decode(data)
+	if block == nil || block.Type != "CERTIFICATE REQUEST" {
+		t.Fatalf("Unexpected PEM data %s", data)
+	}
+	csr, err := x509.ParseCertificateRequest(block.Bytes)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := csr.CheckSignature(); err != nil {
+		t.Fatal(err)
+	}
+	expected := []string{"xn--exmple-cua.com", "*.xn--exmple-cua.com", "www.example.org"}
+	if csr.Subject.CommonName != expected[0] || len(csr.DNSNames) != len(expected) {
+		t.Fatalf("Unexpected names %s %v", csr.Subject.CommonName, csr.DNSNames)
+	}
+	for i, name := range expected {
+		if csr.DNSNames[i] != name {
+			t.Errorf("Expected the name %s, got %s", name, csr.DNSNames[i])
+		}
+	}
+}
+
+func TestEncryptedPrivateKey(t *testing.T) {
+	key, err := certificate.GenerateKey(certificate.KeyECDSA, 384)
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, err := certificate.EncodeEncryptedPrivateKey(key, []byte("passphrase"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := certificate.DecodePrivateKey(data, []byte("wrong")); err == nil {
+		t.Error("A wrong passphrase should be rejected")
+	}
+	decoded, err := certificate.DecodePrivateKey(data, []byte("passphrase"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !key.(*ecdsa.PrivateKey).Equal(decoded) {
+		t.Fatal("The decrypted key differs from the original one")
+	}
+}
+
+// withIterations returns the encrypted PEM key with another PBKDF2
+// iteration count
+func withIterations(t *testing.T, data []byte, iterations int) []byte {
+	type kdfParams struct {
+		Salt           []byte
+		IterationCount int
+		PRF            pkix.AlgorithmIdentifier
+	}
+	type pbes2Params struct {
+		KeyDerivationFunc pkix.AlgorithmIdentifier
+		EncryptionScheme  pkix.AlgorithmIdentifier
+	}
+	var info struct {
+		Algorithm     pkix.AlgorithmIdentifier
+		EncryptedData []byte
+	}
+	var params pbes2Params
+	var kdf kdfParams
+	block, _ := pem.Decode(data)
+	if _, err := asn1.Unmarshal(block.Bytes, &info); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := asn1.Unmarshal(info.Algorithm.Parameters.FullBytes, &params); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := asn1.Unmarshal(params.KeyDerivationFunc.Parameters.FullBytes, &kdf); err != nil {
+		t.Fatal(err)
+	}
+	kdf.IterationCount = iterations
+	var err error
+	if params.KeyDerivationFunc.Parameters.FullBytes, err = asn1.Marshal(kdf); err != nil {
+		t.Fatal(err)
+	}
+	if info.Algorithm.Parameters.FullBytes, err = asn1.Marshal(params); err != nil {
+		t.Fatal(err)
+	}
+	if block.Bytes, err = asn1.Marshal(info); err != nil {
+		t.Fatal(err)
+	}
+	return pem.EncodeToMemory(block)
+}
+
+func TestEncryptedPrivateKeyIterations(t *testing.T) {
+	key, err := certificate.GenerateKey(certificate.KeyECDSA, 256)
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, err := certificate.EncodeEncryptedPrivateKey(key, []byte("passphrase"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	// The key is still decrypted once re-encoded
+	if _, err := certificate.DecodePrivateKey(withIterations(t, data, 100000), []byte("passphrase")); err != nil {
+		t.Fatal(err)
+	}
+	for _, iterations := range []int{0, -1, 1 << 30} {
+		_, err := certificate.DecodePrivateKey(withIterations(t, data, iterations), []byte("passphrase"))
+		if err == nil || !strings.Contains(err.Error(), "iteration count") {
+			t.Errorf("The iteration count %d should be rejected, got %v", iterations, err)
+		}
+	}
+}
+
+func TestWritePrivateKey(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "key.pem")
+	key, err := certificate.GenerateKey(certificate.KeyECDSA, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := certificate.WritePrivateKey(path, key, nil); err != nil {
+		t.Fatal(err)
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.Mode().Perm() != 0600 {
+		t.Fatalf("The key should only be readable by its owner (mode %s)", info.Mode())
+	}
+	data, _ := os.ReadFile(path)
+
+	// An existing key is never overwritten
+	if err := certificate.WritePrivateKey(path, key, nil); !errors.Is(err, fs.ErrExist) {
+		t.Fatalf("Expected an existing file error, got %v", err)
+	}
+	if after, _ := os.ReadFile(path); string(after) != string(data) {
+		t.Fatal("The existing key has been modified")
+	}
+}
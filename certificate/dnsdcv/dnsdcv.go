@@ -0,0 +1,189 @@
+// Package dnsdcv orders certificates whose domains are hosted on
+// LiveDNS and proves the control of the domains with DNS records: the
+// records given by the domain control validation parameters are
+// created, kept until the certificate is issued, then deleted.
+package dnsdcv
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/go-gandi/go-gandi/certificate"
+	"github.com/go-gandi/go-gandi/livedns"
+)
+
+// DefaultTTL is the TTL of the validation records
+const DefaultTTL = 300
+
+// Statuses of the certificates
+const (
+	StatusPending = "pending"
+	StatusValid   = "valid"
+)
+
+// Result is an issued certificate
+type Result struct {
+	Certificate certificate.CertificateType
+	// Data is the PEM encoded certificate
+	Data []byte
+}
+
+// Validator orders certificates and validates them with records
+// created in LiveDNS
+type Validator struct {
+	Certificates *certificate.Certificate
+	LiveDNS      *livedns.LiveDNS
+	// TTL of the validation records, DefaultTTL if zero
+	TTL int
+	// Interval between two checks of the certificate status, 30
+	// seconds if zero
+	Interval time.Duration
+	// Logger receives the progress of the validation when not nil
+	Logger *log.Logger
+}
+
+// record is a validation record to create
+type record struct {
+	zone, name, rrtype, value string
+}
+
+// Order orders a certificate validated with DCVDNS and waits until it
+// is issued, or the context is done. The validation records are
+// deleted in both cases.
+func (v *Validator) Order(ctx context.Context, req certificate.CreateCertificateRequest) (Result, error) {
+	req.DCVMethod = certificate.DCVDNS
+	response, err := v.Certificates.CreateCertificate(req)
+	if err != nil {
+		return Result{}, fmt.Errorf("Fail to order the certificate (error '%w')", err)
+	}
+	v.logf("Certificate %s ordered", response.ID)
+	return v.Validate(ctx, response.ID)
+}
+
+// Validate publishes the validation records of a pending certificate
+// and waits until it is issued, or the context is done
+func (v *Validator) Validate(ctx context.Context, certificateId string) (Result, error) {
+	params, err := v.Certificates.GetDCVParams(certificateId, certificate.DCVDNS)
+	if err != nil {
+		return Result{}, fmt.Errorf("Fail to get the validation parameters (error '%w')", err)
+	}
+	if len(params.DNSRecords) == 0 {
+		return Result{}, fmt.Errorf("The validation parameters of the certificate %s have no DNS record", certificateId)
+	}
+	zones := livedns.NewZoneFinder(v.LiveDNS)
+	var records []record
+	seen := map[string]bool{}
+	for _, line := range params.DNSRecords {
+		fqdn, rrtype, value, err := parseRecord(line)
+		if err != nil {
+			return Result{}, err
+		}
+		// The records of a name and of its wildcard are the same
+		key := strings.ToLower(strings.TrimSuffix(fqdn, ".")) + " " + rrtype + " " + value
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+		zone, name, err := zones.FindZone(fqdn)
+		if err != nil {
+			return Result{}, err
+		}
+		records = append(records, record{zone: zone, name: name, rrtype: rrtype, value: value})
+	}
+
+	defer v.cleanUp(records)
+	for _, r := range records {
+		if err := v.publish(r); err != nil {
+			return Result{}, err
+		}
+		v.logf("Validation record %s %s created in %s", r.name, r.rrtype, r.zone)
+	}
+	if _, err := v.Certificates.ResendDCV(certificateId); err != nil {
+		// The certificate authority checks the records
+		// periodically anyway
+		v.logf("Fail to trigger the validation: %s", err)
+	}
+
+	interval := v.Interval
+	if interval == 0 {
+		interval = 30 * time.Second
+	}
+	for {
+		cert, err := v.Certificates.GetCertificate(certificateId)
+		if err != nil {
+			return Result{}, err
+		}
+		switch cert.Status {
+		case StatusValid:
+			data, err := v.Certificates.GetCertificateData(certificateId)
+			if err != nil {
+				return Result{Certificate: cert}, err
+			}
+			v.logf("Certificate %s issued", certificateId)
+			return Result{Certificate: cert, Data: data}, nil
+		case StatusPending, "":
+		default:
+			return Result{Certificate: cert}, fmt.Errorf("The certificate %s is %s", certificateId, cert.Status)
+		}
+		timer := time.NewTimer(interval)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return Result{Certificate: cert}, ctx.Err()
+		case <-timer.C:
+		}
+	}
+}
+
+// publish creates a validation record. A TXT value is added to its
+// rrset, keeping the other values such as the SPF or site verification
+// records of the name. A CNAME rrset holds a single value, so it is
+// replaced, for instance when left by an earlier validation.
+func (v *Validator) publish(r record) error {
+	ttl := v.TTL
+	if ttl == 0 {
+		ttl = DefaultTTL
+	}
+	var err error
+	if r.rrtype == "CNAME" {
+		_, err = v.LiveDNS.UpdateDomainRecordByNameAndType(r.zone, r.name, r.rrtype, ttl, []string{r.value})
+	} else {
+		_, err = v.LiveDNS.AddRecordValues(r.zone, r.name, r.rrtype, ttl, []string{r.value})
+	}
+	if err != nil {
+		return fmt.Errorf("Fail to create the validation record %s %s in %s (error '%w')", r.name, r.rrtype, r.zone, err)
+	}
+	return nil
+}
+
+// cleanUp removes the values of the validation records, deleting the
+// rrsets left empty
+func (v *Validator) cleanUp(records []record) {
+	for _, r := range records {
+		if _, err := v.LiveDNS.RemoveRecordValues(r.zone, r.name, r.rrtype, []string{r.value}); err != nil {
+			v.logf("Fail to delete the validation record %s %s in %s: %s", r.name, r.rrtype, r.zone, err)
+		}
+	}
+}
+
+// parseRecord splits a record in the zone file format, with optional
+// TTL and class, into its name, type and value
+func parseRecord(line string) (fqdn, rrtype, value string, err error) {
+	fields := strings.Fields(line)
+	for i := 1; i < len(fields)-1; i++ {
+		switch t := strings.ToUpper(fields[i]); t {
+		case "CNAME", "TXT":
+			return fields[0], t, strings.Join(fields[i+1:], " "), nil
+		}
+	}
+	return "", "", "", fmt.Errorf("Cannot parse the validation record '%s'", line)
+}
+
+func (v *Validator) logf(format string, args ...interface{}) {
+	if v.Logger != nil {
+		v.Logger.Printf(format, args...)
+	}
+}
@@ -0,0 +1,166 @@
+package dnsdcv_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/go-gandi/go-gandi/certificate"
+	"github.com/go-gandi/go-gandi/certificate/dnsdcv"
+	"github.com/go-gandi/go-gandi/config"
+	"github.com/go-gandi/go-gandi/livedns"
+	"gopkg.in/h2non/gock.v1"
+)
+
+func TestOrder(t *testing.T) {
+	defer gock.Off()
+	api := "https://api.gandi.net/v5/"
+	gock.New(api).
+		Post("certificate/issued-certs").
+		JSON(certificate.CreateCertificateRequest{CN: "www.example.com", Package: "cert_std_1_0_0", DCVMethod: certificate.DCVDNS}).
+		Reply(202).
+		JSON(certificate.CreateCertificateResponse{ID: "cert-id"})
+	gock.New(api).
+		Post("certificate/issued-certs/cert-id/dcv_params").
+		JSON(map[string]string{"dcv_method": "dns"}).
+		Reply(200).
+		JSON(certificate.DCVParams{
+			DCVMethod:  certificate.DCVDNS,
+			DNSRecords: []string{"_0123abcd.www.example.com. 10800 IN CNAME 4567.89ab.sectigo.com."},
+		})
+	gock.New(api).
+		Get("livedns/domains/_0123abcd.www.example.com").
+		Reply(404).
+		JSON(map[string]string{"message": "Not found"})
+	gock.New(api).
+		Get("livedns/domains/www.example.com").
+		Reply(404).
+		JSON(map[string]string{"message": "Not found"})
+	gock.New(api).
+		Get("livedns/domains/example.com").
+		Reply(200).
+		JSON(livedns.Domain{FQDN: "example.com"})
+	// The CNAME left by an earlier validation, if any, is replaced
+	gock.New(api).
+		Put("livedns/domains/example.com/records/_0123abcd.www/CNAME").
+		JSON(livedns.DomainRecord{RrsetType: "CNAME", RrsetTTL: 300, RrsetValues: []string{"4567.89ab.sectigo.com."}}).
+		Reply(201).
+		JSON(map[string]string{"message": "DNS Record Created"})
+	gock.New(api).
+		Put("certificate/issued-certs/cert-id/dcv").
+		Reply(200).
+		JSON(map[string]string{"message": "The validation has been restarted"})
+	gock.New(api).
+		Get("certificate/issued-certs/cert-id").
+		Reply(200).
+		JSON(certificate.CertificateType{ID: "cert-id", Status: "pending"})
+	gock.New(api).
+		Get("certificate/issued-certs/cert-id").
+		Reply(200).
+		JSON(certificate.CertificateType{ID: "cert-id", Status: "valid"})
+	gock.New(api).
+		Get("certificate/issued-certs/cert-id/crt").
+		Reply(200).
+		BodyString("-----BEGIN CERTIFICATE-----\n")
+	gock.New(api).
+		Get("livedns/domains/example.com/records/_0123abcd.www/CNAME").
+		Reply(200).
+		JSON(livedns.DomainRecord{RrsetName: "_0123abcd.www", RrsetType: "CNAME", RrsetTTL: 300, RrsetValues: []string{"4567.89ab.sectigo.com."}})
+	gock.New(api).
+		Delete("livedns/domains/example.com/records/_0123abcd.www/CNAME").
+		Reply(204)
+	gock.New(api).
+		Get("livedns/domains/example.com/records/_0123abcd.www/CNAME").
+		Reply(404).
+		JSON(map[string]string{"message": "Not found"})
+
+	validator := dnsdcv.Validator{
+		Certificates: certificate.New(config.Config{}),
+		LiveDNS:      livedns.New(config.Config{}),
+		Interval:     time.Millisecond,
+	}
+	result, err := validator.Order(context.Background(), certificate.CreateCertificateRequest{CN: "www.example.com", Package: "cert_std_1_0_0"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Certificate.Status != "valid" || string(result.Data) != "-----BEGIN CERTIFICATE-----\n" {
+		t.Fatalf("Unexpected result %+v", result)
+	}
+	if !gock.IsDone() {
+		t.Fatal("All the expected requests have not been sent")
+	}
+}
+
+func TestValidateKeepsOtherTXTValues(t *testing.T) {
+	defer gock.Off()
+	api := "https://api.gandi.net/v5/"
+	verification := `"site-verification=abc"`
+	token := `"0123456789abcdef"`
+	gock.New(api).
+		Post("certificate/issued-certs/cert-id/dcv_params").
+		Reply(200).
+		JSON(certificate.DCVParams{
+			DCVMethod: certificate.DCVDNS,
+			// The apex and the wildcard are validated with the same
+			// record
+			DNSRecords: []string{
+				"_dnsauth.example.com. 300 IN TXT " + token,
+				"_dnsauth.example.com. 300 IN TXT " + token,
+			},
+		})
+	gock.New(api).
+		Get("livedns/domains/_dnsauth.example.com").
+		Reply(404).
+		JSON(map[string]string{"message": "Not found"})
+	gock.New(api).
+		Get("livedns/domains/example.com").
+		Reply(200).
+		JSON(livedns.Domain{FQDN: "example.com"})
+	gock.New(api).
+		Get("livedns/domains/example.com/records/_dnsauth/TXT").
+		Reply(200).
+		JSON(livedns.DomainRecord{RrsetName: "_dnsauth", RrsetType: "TXT", RrsetTTL: 3600, RrsetValues: []string{verification}})
+	gock.New(api).
+		Put("livedns/domains/example.com/records/_dnsauth/TXT").
+		JSON(livedns.DomainRecord{RrsetType: "TXT", RrsetTTL: 3600, RrsetValues: []string{verification, token}}).
+		Reply(201).
+		JSON(map[string]string{"message": "DNS Record Created"})
+	gock.New(api).
+		Get("livedns/domains/example.com/records/_dnsauth/TXT").
+		Times(2).
+		Reply(200).
+		JSON(livedns.DomainRecord{RrsetName: "_dnsauth", RrsetType: "TXT", RrsetTTL: 3600, RrsetValues: []string{verification, token}})
+	gock.New(api).
+		Put("certificate/issued-certs/cert-id/dcv").
+		Reply(200).
+		JSON(map[string]string{"message": "The validation has been restarted"})
+	gock.New(api).
+		Get("certificate/issued-certs/cert-id").
+		Reply(200).
+		JSON(certificate.CertificateType{ID: "cert-id", Status: "valid"})
+	gock.New(api).
+		Get("certificate/issued-certs/cert-id/crt").
+		Reply(200).
+		BodyString("-----BEGIN CERTIFICATE-----\n")
+	gock.New(api).
+		Put("livedns/domains/example.com/records/_dnsauth/TXT").
+		JSON(livedns.DomainRecord{RrsetType: "TXT", RrsetTTL: 3600, RrsetValues: []string{verification}}).
+		Reply(201).
+		JSON(map[string]string{"message": "DNS Record Created"})
+	gock.New(api).
+		Get("livedns/domains/example.com/records/_dnsauth/TXT").
+		Reply(200).
+		JSON(livedns.DomainRecord{RrsetName: "_dnsauth", RrsetType: "TXT", RrsetTTL: 3600, RrsetValues: []string{verification}})
+
+	validator := dnsdcv.Validator{
+		Certificates: certificate.New(config.Config{}),
+		LiveDNS:      livedns.New(config.Config{}),
+		Interval:     time.Millisecond,
+	}
+	if _, err := validator.Validate(context.Background(), "cert-id"); err != nil {
+		t.Fatal(err)
+	}
+	if !gock.IsDone() {
+		t.Fatal("All the expected requests have not been sent")
+	}
+}
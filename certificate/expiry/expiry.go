@@ -0,0 +1,176 @@
+// Package expiry finds the certificates of an account which expire
+// soon, to alert before a site or a web redirection stops being served
+// over HTTPS.
+package expiry
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/go-gandi/go-gandi/certificate"
+	"github.com/go-gandi/go-gandi/domain"
+	"github.com/go-gandi/go-gandi/types"
+)
+
+// Kinds of expiring certificates
+const (
+	KindCertificate    = "certificate"
+	KindWebRedirection = "web-redirection"
+)
+
+// Statuses of the certificates
+const (
+	// StatusValid is the status of the certificates in use
+	StatusValid = "valid"
+	// StatusUnknown is reported for a web redirection whose
+	// certificate status is neither given nor readable, for instance
+	// when its certificate belongs to another account
+	StatusUnknown = "unknown"
+)
+
+// Expiring is a certificate ending within the scanned window, or a
+// web redirection whose certificate is not valid
+type Expiring struct {
+	Kind string `json:"kind"`
+	// ID is the ID of the certificate, empty for a web redirection
+	// without certificate
+	ID string `json:"id,omitempty"`
+	// Name is the CN of the certificate or the host of the web
+	// redirection
+	Name   string     `json:"name"`
+	Status string     `json:"status"`
+	EndsAt *time.Time `json:"ends_at,omitempty"`
+	// Expired tells if EndsAt is already past
+	Expired bool `json:"expired"`
+}
+
+// Scanner lists the expiring certificates of an account
+type Scanner struct {
+	Certificates *certificate.Certificate
+	// Domains is used to check the certificates of the web
+	// redirections of every domain, which are skipped if it is nil
+	Domains *domain.Domain
+	// Now returns the current time, time.Now if nil
+	Now func() time.Time
+}
+
+// Scan returns the valid certificates ending before now + within, and
+// the HTTPS web redirections whose certificate ends before then or is
+// not valid. The result is sorted by end date, the soonest first.
+func (s *Scanner) Scan(within time.Duration) ([]Expiring, error) {
+	now := time.Now()
+	if s.Now != nil {
+		now = s.Now()
+	}
+	deadline := now.Add(within)
+
+	certificates, err := s.Certificates.ListCertificates()
+	if err != nil {
+		return nil, fmt.Errorf("Fail to list the certificates (error '%w')", err)
+	}
+	byID := map[string]certificate.CertificateType{}
+	var expiring []Expiring
+	for _, cert := range certificates {
+		byID[cert.ID] = cert
+		if cert.Status != StatusValid || !endsBefore(cert, deadline) {
+			continue
+		}
+		expiring = append(expiring, newExpiring(KindCertificate, cert.CN, cert, now))
+	}
+
+	if s.Domains != nil {
+		redirections, err := s.scanWebRedirections(byID, now, deadline)
+		if err != nil {
+			return nil, err
+		}
+		expiring = append(expiring, redirections...)
+	}
+
+	sort.SliceStable(expiring, func(i, j int) bool {
+		a, b := expiring[i].EndsAt, expiring[j].EndsAt
+		switch {
+		case a == nil:
+			return b != nil
+		case b == nil:
+			return false
+		}
+		return a.Before(*b)
+	})
+	return expiring, nil
+}
+
+func (s *Scanner) scanWebRedirections(byID map[string]certificate.CertificateType, now, deadline time.Time) ([]Expiring, error) {
+	domains, err := s.Domains.ListDomains()
+	if err != nil {
+		return nil, fmt.Errorf("Fail to list the domains (error '%w')", err)
+	}
+	var expiring []Expiring
+	for _, d := range domains {
+		redirections, err := s.Domains.ListWebRedirections(d.FQDN)
+		if err != nil {
+			return nil, fmt.Errorf("Fail to list the web redirections of '%s' (error '%w')", d.FQDN, err)
+		}
+		for _, redirection := range redirections {
+			if !strings.HasPrefix(redirection.Protocol, "https") && redirection.CertificateUUID == "" {
+				continue
+			}
+			cert, found, err := s.redirectionCertificate(byID, redirection.CertificateUUID)
+			if err != nil {
+				return nil, err
+			}
+			status := redirection.CertificateStatus
+			if status == "" && found {
+				status = cert.Status
+			}
+			if status == "" {
+				status = StatusUnknown
+			}
+			if status == StatusValid && (!found || !endsBefore(cert, deadline)) {
+				continue
+			}
+			item := newExpiring(KindWebRedirection, redirection.Host, cert, now)
+			item.ID = redirection.CertificateUUID
+			item.Status = status
+			expiring = append(expiring, item)
+		}
+	}
+	return expiring, nil
+}
+
+// redirectionCertificate returns the certificate of a web redirection,
+// which is not always part of the certificates of the account
+func (s *Scanner) redirectionCertificate(byID map[string]certificate.CertificateType, id string) (certificate.CertificateType, bool, error) {
+	if id == "" {
+		return certificate.CertificateType{}, false, nil
+	}
+	if cert, ok := byID[id]; ok {
+		return cert, true, nil
+	}
+	cert, err := s.Certificates.GetCertificate(id)
+	var e *types.RequestError
+	if errors.As(err, &e) && (e.StatusCode == http.StatusNotFound || e.StatusCode == http.StatusForbidden) {
+		return cert, false, nil
+	}
+	if err != nil {
+		return cert, false, fmt.Errorf("Fail to get the certificate '%s' (error '%w')", id, err)
+	}
+	byID[id] = cert
+	return cert, true, nil
+}
+
+func endsBefore(cert certificate.CertificateType, deadline time.Time) bool {
+	return cert.Dates != nil && cert.Dates.EndsAt != nil && cert.Dates.EndsAt.Before(deadline)
+}
+
+func newExpiring(kind, name string, cert certificate.CertificateType, now time.Time) Expiring {
+	item := Expiring{Kind: kind, ID: cert.ID, Name: name, Status: cert.Status}
+	if cert.Dates != nil && cert.Dates.EndsAt != nil {
+		item.EndsAt = cert.Dates.EndsAt
+		item.Expired = cert.Dates.EndsAt.Before(now)
+	}
+	return item
+}
@@ -0,0 +1,85 @@
+package expiry_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/go-gandi/go-gandi/certificate"
+	"github.com/go-gandi/go-gandi/certificate/expiry"
+	"github.com/go-gandi/go-gandi/config"
+	"github.com/go-gandi/go-gandi/domain"
+	"gopkg.in/h2non/gock.v1"
+)
+
+func TestScan(t *testing.T) {
+	defer gock.Off()
+	api := "https://api.gandi.net/v5/"
+	now := time.Date(2022, 6, 1, 0, 0, 0, 0, time.UTC)
+	at := func(days int) *time.Time {
+		t := now.AddDate(0, 0, days)
+		return &t
+	}
+	gock.New(api).
+		Get("certificate/issued-certs").
+		Reply(200).
+		JSON([]certificate.CertificateType{
+			{ID: "soon", CN: "soon.example.com", Status: "valid", Dates: &certificate.CertificateDates{EndsAt: at(10)}},
+			{ID: "later", CN: "later.example.com", Status: "valid", Dates: &certificate.CertificateDates{EndsAt: at(90)}},
+			{ID: "expired", CN: "expired.example.com", Status: "valid", Dates: &certificate.CertificateDates{EndsAt: at(-1)}},
+			{ID: "revoked", CN: "revoked.example.com", Status: "revoked", Dates: &certificate.CertificateDates{EndsAt: at(5)}},
+		})
+	gock.New(api).
+		Get("domain/domains").
+		Reply(200).
+		JSON([]domain.ListResponse{{FQDN: "example.com"}})
+	gock.New(api).
+		Get("domain/domains/example.com/webredirs").
+		Reply(200).
+		JSON([]domain.WebRedirection{
+			{Host: "plain.example.com", Protocol: "http"},
+			{Host: "later.example.com", Protocol: "https", CertificateStatus: "valid", CertificateUUID: "later"},
+			{Host: "www.example.com", Protocol: "https", CertificateStatus: "valid", CertificateUUID: "redir"},
+			{Host: "failed.example.com", Protocol: "https", CertificateStatus: "failed"},
+			// The certificate belongs to another account
+			{Host: "shared.example.com", Protocol: "https", CertificateUUID: "shared"},
+		})
+	gock.New(api).
+		Get("certificate/issued-certs/redir").
+		Reply(200).
+		JSON(certificate.CertificateType{ID: "redir", CN: "www.example.com", Status: "valid", Dates: &certificate.CertificateDates{EndsAt: at(20)}})
+	gock.New(api).
+		Get("certificate/issued-certs/shared").
+		Reply(403).
+		JSON(map[string]string{"message": "Access was denied to this resource."})
+
+	cfg := config.Config{}
+	scanner := expiry.Scanner{
+		Certificates: certificate.New(cfg),
+		Domains:      domain.New(cfg),
+		Now:          func() time.Time { return now },
+	}
+	expiring, err := scanner.Scan(30 * 24 * time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := []expiry.Expiring{
+		{Kind: expiry.KindWebRedirection, Name: "failed.example.com", Status: "failed"},
+		{Kind: expiry.KindWebRedirection, ID: "shared", Name: "shared.example.com", Status: expiry.StatusUnknown},
+		{Kind: expiry.KindCertificate, ID: "expired", Name: "expired.example.com", Status: "valid", EndsAt: at(-1), Expired: true},
+		{Kind: expiry.KindCertificate, ID: "soon", Name: "soon.example.com", Status: "valid", EndsAt: at(10)},
+		{Kind: expiry.KindWebRedirection, ID: "redir", Name: "www.example.com", Status: "valid", EndsAt: at(20)},
+	}
+	if len(expiring) != len(expected) {
+		t.Fatalf("Expected %d expiring certificates, got %+v", len(expected), expiring)
+	}
+	for i, e := range expected {
+		got := expiring[i]
+		if got.Kind != e.Kind || got.ID != e.ID || got.Name != e.Name || got.Status != e.Status || got.Expired != e.Expired ||
+			(got.EndsAt == nil) != (e.EndsAt == nil) || (e.EndsAt != nil && !got.EndsAt.Equal(*e.EndsAt)) {
+			t.Errorf("Expected %+v, got %+v", e, got)
+		}
+	}
+	if !gock.IsDone() {
+		t.Fatal("All the expected requests have not been sent")
+	}
+}
@@ -0,0 +1,117 @@
+package certificate
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/rand"
+	"crypto/sha1" //nolint: gosec
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/asn1"
+	"encoding/binary"
+	"fmt"
+	"time"
+	"unicode/utf16"
+)
+
+// The Java KeyStore format is described by the sun.security.provider
+// JavaKeyStore and KeyProtector classes of OpenJDK. Its SHA-1 based
+// protection is weak, so PKCS12 should be preferred with Java 9 and
+// later, which read PKCS #12 keystores.
+var oidJavaKeyProtector = asn1.ObjectIdentifier{1, 3, 6, 1, 4, 1, 42, 2, 17, 1, 1}
+
+const (
+	jksMagic   = 0xfeedfeed
+	jksVersion = 2
+	// jksPrivateKeyEntry is the tag of a private key entry
+	jksPrivateKeyEntry = 1
+)
+
+// JKS returns a Java KeyStore holding the private key and the chain
+// of the certificate under the alias. The password protects both the
+// key and the keystore.
+func (b *Bundle) JKS(key crypto.Signer, alias, password string) ([]byte, error) {
+	if err := b.checkKey(key); err != nil {
+		return nil, err
+	}
+	if alias == "" || len(alias) > 0xffff || bytes.IndexByte([]byte(alias), 0) >= 0 {
+		return nil, fmt.Errorf("Invalid keystore alias '%s'", alias)
+	}
+	der, err := x509.MarshalPKCS8PrivateKey(key)
+	if err != nil {
+		return nil, err
+	}
+	pass := jksPassword(password)
+	protected, err := protectJKSKey(der, pass)
+	if err != nil {
+		return nil, err
+	}
+
+	var buf bytes.Buffer
+	write := func(v interface{}) { _ = binary.Write(&buf, binary.BigEndian, v) }
+	writeUTF := func(s string) {
+		write(uint16(len(s)))
+		buf.WriteString(s)
+	}
+	write(uint32(jksMagic))
+	write(uint32(jksVersion))
+	write(uint32(1))
+	write(uint32(jksPrivateKeyEntry))
+	writeUTF(alias)
+	write(time.Now().UnixNano() / int64(time.Millisecond))
+	write(uint32(len(protected)))
+	buf.Write(protected)
+	chain := append([]*x509.Certificate{b.Certificate}, b.Intermediates...)
+	write(uint32(len(chain)))
+	for _, cert := range chain {
+		writeUTF("X.509")
+		write(uint32(len(cert.Raw)))
+		buf.Write(cert.Raw)
+	}
+
+	digest := sha1.New() //nolint: gosec
+	digest.Write(pass)
+	digest.Write([]byte("Mighty Aphrodite"))
+	digest.Write(buf.Bytes())
+	buf.Write(digest.Sum(nil))
+	return buf.Bytes(), nil
+}
+
+// jksPassword returns the password as UTF-16 big endian bytes
+func jksPassword(password string) []byte {
+	units := utf16.Encode([]rune(password))
+	pass := make([]byte, 2*len(units))
+	for i, u := range units {
+		binary.BigEndian.PutUint16(pass[2*i:], u)
+	}
+	return pass
+}
+
+// protectJKSKey encrypts a PKCS #8 key as the KeyProtector class
+// does: the key is XORed with a SHA-1 keystream derived from the
+// password and a random salt, and followed by a SHA-1 checksum
+func protectJKSKey(key, pass []byte) ([]byte, error) {
+	salt := make([]byte, sha1.Size)
+	if _, err := rand.Read(salt); err != nil {
+		return nil, err
+	}
+	encrypted := make([]byte, len(key))
+	digest := salt
+	for i := 0; i < len(key); i += sha1.Size {
+		h := sha1.New() //nolint: gosec
+		h.Write(pass)
+		h.Write(digest)
+		digest = h.Sum(nil)
+		for j := 0; j < sha1.Size && i+j < len(key); j++ {
+			encrypted[i+j] = key[i+j] ^ digest[j]
+		}
+	}
+	check := sha1.New() //nolint: gosec
+	check.Write(pass)
+	check.Write(key)
+	protected := append(append(append([]byte{}, salt...), encrypted...), check.Sum(nil)...)
+	return asn1.Marshal(encryptedPrivateKeyInfo{
+		Algorithm:     pkix.AlgorithmIdentifier{Algorithm: oidJavaKeyProtector, Parameters: asn1.NullRawValue},
+		EncryptedData: protected,
+	})
+}
@@ -53,9 +53,89 @@ type CertificateContact struct {
 	Zip        string `json:"zip,omitempty"`
 }
 
+// DCVMethod is the method proving the control of the domains of a
+// certificate to the certificate authority
+type DCVMethod string
+
+const (
+	// DCVEmail sends a validation link to an administrative address
+	// of the domain
+	DCVEmail DCVMethod = "email"
+	// DCVDNS requires a CNAME record in the zone of the domain
+	DCVDNS DCVMethod = "dns"
+	// DCVFile requires a file on the web server of the domain
+	DCVFile DCVMethod = "file"
+	// DCVHTTP is DCVFile served over HTTP only
+	DCVHTTP DCVMethod = "http"
+	// DCVHTTPS is DCVFile served over HTTPS only
+	DCVHTTPS DCVMethod = "https"
+)
+
+func (m DCVMethod) valid() bool {
+	switch m {
+	case DCVEmail, DCVDNS, DCVFile, DCVHTTP, DCVHTTPS:
+		return true
+	}
+	return false
+}
+
+// CreateCertificateRequest orders a certificate. The CN and the
+// alternative names are read from the CSR when it is given.
 type CreateCertificateRequest struct {
-	CN      string `json:"cn"`
+	CN      string `json:"cn,omitempty"`
 	Package string `json:"package"`
+	// CSR is the PEM encoded certificate signing request
+	CSR      string   `json:"csr,omitempty"`
+	AltNames []string `json:"altnames,omitempty"`
+	// DCVMethod is the domain control validation method
+	DCVMethod DCVMethod `json:"dcv_method,omitempty"`
+	// Duration of the certificate in years
+	Duration int                 `json:"duration,omitempty"`
+	Contact  *CertificateContact `json:"contact,omitempty"`
+}
+
+// RenewCertificateRequest renews a certificate. The CSR of the
+// current certificate is used when none is given.
+type RenewCertificateRequest struct {
+	// CSR is the PEM encoded certificate signing request
+	CSR       string    `json:"csr,omitempty"`
+	DCVMethod DCVMethod `json:"dcv_method,omitempty"`
+	// Duration of the renewed certificate in years
+	Duration int `json:"duration,omitempty"`
+}
+
+// reissueRequest replaces the key of a certificate with the one of a
+// new CSR
+type reissueRequest struct {
+	CSR string `json:"csr"`
+}
+
+// DCVParams are the parameters of the domain control validation of a
+// certificate
+type DCVParams struct {
+	DCVMethod DCVMethod `json:"dcv_method"`
+	// FQDNs are the names to validate
+	FQDNs []string `json:"fqdns"`
+	// MD5 and SHA256 are the hashes of the CSR the validation
+	// records and files are derived from
+	MD5    string `json:"md5"`
+	SHA256 string `json:"sha256"`
+	// DNSRecords are the records to create for DCVDNS, in the zone
+	// file format
+	DNSRecords []string `json:"dns_records,omitempty"`
+	// FileName and FileContent are the file to publish under
+	// /.well-known/pki-validation/ for DCVFile, DCVHTTP and DCVHTTPS
+	FileName    string `json:"file_name,omitempty"`
+	FileContent string `json:"file_content,omitempty"`
+	// Emails are the addresses the validation can be sent to with
+	// DCVEmail
+	Emails  []string `json:"emails,omitempty"`
+	Message string   `json:"message,omitempty"`
+}
+
+// dcvRequest selects the validation method of a certificate
+type dcvRequest struct {
+	DCVMethod DCVMethod `json:"dcv_method"`
 }
 
 type CreateCertificateResponse struct {
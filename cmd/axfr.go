@@ -1,5 +1,11 @@
 package main
 
+import (
+	"fmt"
+
+	"github.com/go-gandi/go-gandi/livedns"
+)
+
 type liveDNSGetTSIGsCmd struct {
 	Format string `kong:"arg,optional,enum='bind,nsd,powerdns,knot,none',default='none',help='The format of the TSIG config (bind, nsd, powerdns, knot)'"`
 }
@@ -7,18 +13,16 @@ type liveDNSGetTSIGsCmd struct {
 func (d *liveDNSGetTSIGsCmd) Run(g *globals) error {
 	fqdn := c.LiveDNS.Manage.Name.Name
 	l := g.liveDNSHandle
-	switch d.Format {
-	case "bind":
-		return jsonPrint(l.GetTsigBIND(fqdn))
-	case "nsd":
-		return jsonPrint(l.GetTsigNSD(fqdn))
-	case "powerdns":
-		return jsonPrint(l.GetTsigPowerDNS(fqdn))
-	case "knot":
-		return jsonPrint(l.GetTsigKnot(fqdn))
-	default:
-		return jsonPrint(l.GetTSIGKey(fqdn))
+	keys, err := l.GetDomainTSIGKeys(fqdn)
+	if d.Format == "none" || err != nil {
+		return jsonPrint(keys, err)
+	}
+	for _, key := range keys {
+		if err := textPrint(l.GetTSIGKeyConfig(key.ID, livedns.SecondaryFormat(d.Format))); err != nil {
+			return err
+		}
 	}
+	return nil
 }
 
 type liveDNSAddTSIGToDomainCmd struct {
@@ -28,11 +32,11 @@ type liveDNSAddTSIGToDomainCmd struct {
 func (d *liveDNSAddTSIGToDomainCmd) Run(g *globals) error {
 	fqdn := c.LiveDNS.Manage.Name.Name
 	l := g.liveDNSHandle
-	return noPrint(l.AddTsigToDomain(fqdn, d.UUID))
+	return jsonPrint(l.AssociateTSIGKeyWithDomain(fqdn, d.UUID))
 }
 
 type liveDNSRemoveTSIGFromDomainCmd struct {
-	UUID string `kong:"arg,help='The UUID of the TSIG to add'"`
+	UUID string `kong:"arg,help='The UUID of the TSIG to remove'"`
 }
 
 func (d *liveDNSRemoveTSIGFromDomainCmd) Run(g *globals) error {
@@ -48,7 +52,7 @@ type liveDNSAddAXFRSlaveCmd struct {
 func (d *liveDNSAddAXFRSlaveCmd) Run(g *globals) error {
 	fqdn := c.LiveDNS.Manage.Name.Name
 	l := g.liveDNSHandle
-	return noPrint(l.AddSlaveToDomain(fqdn, d.IP))
+	return noPrint(l.AddSecondary(fqdn, d.IP))
 }
 
 type liveDNSListAXFRSlavesCmd struct{}
@@ -56,7 +60,7 @@ type liveDNSListAXFRSlavesCmd struct{}
 func (d *liveDNSListAXFRSlavesCmd) Run(g *globals) error {
 	fqdn := c.LiveDNS.Manage.Name.Name
 	l := g.liveDNSHandle
-	return jsonPrint(l.ListSlavesInDomain(fqdn))
+	return jsonPrint(l.ListSecondaries(fqdn))
 }
 
 type liveDNSRemoveAXFRSlaveCmd struct {
@@ -66,5 +70,40 @@ type liveDNSRemoveAXFRSlaveCmd struct {
 func (d *liveDNSRemoveAXFRSlaveCmd) Run(g *globals) error {
 	fqdn := c.LiveDNS.Manage.Name.Name
 	l := g.liveDNSHandle
-	return noPrint(l.DelSlaveFromDomain(fqdn, d.IP))
+	return noPrint(l.RemoveSecondary(fqdn, d.IP))
+}
+
+type liveDNSListTSIGKeysCmd struct{}
+
+func (d *liveDNSListTSIGKeysCmd) Run(g *globals) error {
+	l := g.liveDNSHandle
+	return jsonPrint(l.GetTSIGKeys())
+}
+
+type liveDNSCreateTSIGKeyCmd struct {
+	FQDN string `kong:"arg,optional,help='A domain to associate the key with'"`
+}
+
+func (d *liveDNSCreateTSIGKeyCmd) Run(g *globals) error {
+	l := g.liveDNSHandle
+	key, err := l.CreateAccountTSIGKey()
+	if err != nil || d.FQDN == "" {
+		return jsonPrint(key, err)
+	}
+	if _, err := l.AssociateTSIGKeyWithDomain(d.FQDN, key.ID); err != nil {
+		return jsonPrint(nil, fmt.Errorf("The key %s has been created but not associated with %s (error '%w')", key.ID, d.FQDN, err))
+	}
+	return jsonPrint(key, nil)
+}
+
+type liveDNSSecondaryConfigCmd struct {
+	Format    string   `kong:"arg,enum='bind,nsd,powerdns,knot',help='The format of the configuration (bind, nsd, powerdns, knot)'"`
+	Key       string   `kong:"arg,help='The UUID of the TSIG key'"`
+	Zones     []string `kong:"arg,help='The zones to transfer'"`
+	Primaries []string `kong:"name='primary',required,help='The address of a LiveDNS primary nameserver (repeatable)'"`
+}
+
+func (d *liveDNSSecondaryConfigCmd) Run(g *globals) error {
+	l := g.liveDNSHandle
+	return textPrint(l.GenerateSecondaryConfig(livedns.SecondaryFormat(d.Format), d.Key, d.Primaries, d.Zones))
 }
@@ -7,6 +7,15 @@ type certificateCmd struct {
 	Delete      certificateDeleteCmd      `kong:"cmd,help='Delete a certificate'"`
 	Create      certificateCreateCmd      `kong:"cmd,help='Create a certificate'"`
 	ListPackage certificateListPackageCmd `kong:"cmd,help='List certificate packages'"`
+	DCVParams   certificateDCVParamsCmd   `kong:"cmd,name='dcv-params',help='Display the domain control validation parameters of a certificate'"`
+	ResendDCV   certificateResendDCVCmd   `kong:"cmd,name='resend-dcv',help='Check the domain control validation of a certificate again'"`
+	UpdateDCV   certificateUpdateDCVCmd   `kong:"cmd,name='update-dcv',help='Change the domain control validation method of a certificate'"`
+	ValidateDNS certificateValidateDNSCmd `kong:"cmd,name='validate-dns',help='Create the validation records of a certificate in LiveDNS and print the certificate once issued'"`
+	CSR         certificateCSRCmd         `kong:"cmd,name='csr',help='Generate a private key and print a certificate signing request'"`
+	Bundle      certificateBundleCmd      `kong:"cmd,help='Export a certificate with its intermediate certificates, as PEM, PKCS #12 or JKS'"`
+	Renew       certificateRenewCmd       `kong:"cmd,help='Renew a certificate'"`
+	Reissue     certificateReissueCmd     `kong:"cmd,help='Reissue a certificate for the key of a new CSR'"`
+	Expiring    certificateExpiringCmd    `kong:"cmd,help='List the certificates and web redirections whose certificate expires soon, exit with an error if any'"`
 }
 
 type certificateListCmd struct{}
@@ -14,12 +23,42 @@ type certificateDeleteCmd struct {
 	CertificateId string `kong:"arg,help='The certificate ID'"`
 }
 type certificateCreateCmd struct {
-	CN      string `kong:"arg,help='The certificate CN'"`
-	Package string `kong:"arg,help='The certificate package (available packages can be listed with the list-package command)'"`
+	CN        string   `kong:"arg,help='The certificate CN'"`
+	Package   string   `kong:"arg,help='The certificate package (available packages can be listed with the list-package command)'"`
+	CSR       string   `kong:"name='csr',help='A file containing the PEM encoded CSR, - for stdin'"`
+	AltNames  []string `kong:"name='altname',help='An alternative name of the certificate'"`
+	DCVMethod string   `kong:"name='dcv-method',enum=',email,dns,file,http,https',default='',help='The domain control validation method (email, dns, file, http, https)'"`
+	Duration  int      `kong:"help='The duration of the certificate in years'"`
+}
+
+type certificateDCVParamsCmd struct {
+	CertificateId string `kong:"arg,help='The certificate ID'"`
+	DCVMethod     string `kong:"arg,enum='email,dns,file,http,https',help='The domain control validation method (email, dns, file, http, https)'"`
+}
+
+type certificateResendDCVCmd struct {
+	CertificateId string `kong:"arg,help='The certificate ID'"`
+}
+
+type certificateUpdateDCVCmd struct {
+	CertificateId string `kong:"arg,help='The certificate ID'"`
+	DCVMethod     string `kong:"arg,enum='email,dns,file,http,https',help='The domain control validation method (email, dns, file, http, https)'"`
 }
 
 type certificateListPackageCmd struct{}
 
+type certificateRenewCmd struct {
+	CertificateId string `kong:"arg,help='The certificate ID'"`
+	CSR           string `kong:"name='csr',help='A file containing the PEM encoded CSR, - for stdin (the current CSR is used by default)'"`
+	DCVMethod     string `kong:"name='dcv-method',enum=',email,dns,file,http,https',default='',help='The domain control validation method (email, dns, file, http, https)'"`
+	Duration      int    `kong:"help='The duration of the renewed certificate in years'"`
+}
+
+type certificateReissueCmd struct {
+	CertificateId string `kong:"arg,help='The certificate ID'"`
+	CSR           string `kong:"arg,name='csr',help='A file containing the PEM encoded CSR, - for stdin'"`
+}
+
 func (cmd *certificateListCmd) Run(g *globals) error {
 	s := g.certificateHandle
 	return jsonPrint(s.ListCertificates())
@@ -32,14 +71,64 @@ func (cmd *certificateDeleteCmd) Run(g *globals) error {
 
 func (cmd *certificateCreateCmd) Run(g *globals) error {
 	s := g.certificateHandle
-	return jsonPrint(s.CreateCertificate(
-		certificate.CreateCertificateRequest{
-			CN:      cmd.CN,
-			Package: cmd.Package,
-		}))
+	req := certificate.CreateCertificateRequest{
+		CN:        cmd.CN,
+		Package:   cmd.Package,
+		AltNames:  cmd.AltNames,
+		DCVMethod: certificate.DCVMethod(cmd.DCVMethod),
+		Duration:  cmd.Duration,
+	}
+	if cmd.CSR != "" {
+		csr, err := readInput(cmd.CSR)
+		if err != nil {
+			return err
+		}
+		req.CSR = string(csr)
+	}
+	return jsonPrint(s.CreateCertificate(req))
+}
+
+func (cmd *certificateDCVParamsCmd) Run(g *globals) error {
+	s := g.certificateHandle
+	return jsonPrint(s.GetDCVParams(cmd.CertificateId, certificate.DCVMethod(cmd.DCVMethod)))
+}
+
+func (cmd *certificateResendDCVCmd) Run(g *globals) error {
+	s := g.certificateHandle
+	return jsonPrint(s.ResendDCV(cmd.CertificateId))
+}
+
+func (cmd *certificateUpdateDCVCmd) Run(g *globals) error {
+	s := g.certificateHandle
+	return jsonPrint(s.UpdateDCV(cmd.CertificateId, certificate.DCVMethod(cmd.DCVMethod)))
 }
 
 func (cmd *certificateListPackageCmd) Run(g *globals) error {
 	s := g.certificateHandle
 	return jsonPrint(s.ListPackages())
 }
+
+func (cmd *certificateRenewCmd) Run(g *globals) error {
+	s := g.certificateHandle
+	req := certificate.RenewCertificateRequest{
+		DCVMethod: certificate.DCVMethod(cmd.DCVMethod),
+		Duration:  cmd.Duration,
+	}
+	if cmd.CSR != "" {
+		csr, err := readInput(cmd.CSR)
+		if err != nil {
+			return err
+		}
+		req.CSR = string(csr)
+	}
+	return jsonPrint(s.RenewCertificate(cmd.CertificateId, req))
+}
+
+func (cmd *certificateReissueCmd) Run(g *globals) error {
+	s := g.certificateHandle
+	csr, err := readInput(cmd.CSR)
+	if err != nil {
+		return err
+	}
+	return jsonPrint(s.ReissueCertificate(cmd.CertificateId, string(csr)))
+}
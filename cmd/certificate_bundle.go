@@ -0,0 +1,68 @@
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/go-gandi/go-gandi/certificate"
+)
+
+type certificateBundleCmd struct {
+	ID           string `kong:"arg,help='The certificate ID'"`
+	Format       string `kong:"enum='pem,pkcs12,jks',default='pem',help='The format of the bundle (pem, pkcs12, jks)'"`
+	Key          string `kong:"help='The file of the private key, required by the pkcs12 and jks formats'"`
+	KeyEncrypted bool   `kong:"help='Read the passphrase of the private key from a prompt'"`
+	Alias        string `kong:"default='server',help='The alias of the key entry of a jks keystore'"`
+	Out          string `kong:"help='The file the bundle is written to, required by the pkcs12 and jks formats'"`
+	NoVerify     bool   `kong:"help='Do not check the certificate chain with the system roots'"`
+}
+
+func (cmd *certificateBundleCmd) Run(g *globals) error {
+	if cmd.Format != "pem" && (cmd.Key == "" || cmd.Out == "") {
+		return fmt.Errorf("--key and --out are required by the %s format", cmd.Format)
+	}
+	get := g.certificateHandle.GetCertificateBundle
+	if cmd.NoVerify {
+		get = g.certificateHandle.GetCertificateBundleUnverified
+	}
+	bundle, err := get(cmd.ID)
+	if err != nil {
+		return err
+	}
+	if cmd.Format == "pem" {
+		if cmd.Out == "" {
+			fmt.Print(string(bundle.FullChain()))
+			return nil
+		}
+		return os.WriteFile(cmd.Out, bundle.FullChain(), 0644)
+	}
+
+	var passphrase string
+	if cmd.KeyEncrypted {
+		if passphrase, err = promptSecret("Key passphrase", false); err != nil {
+			return err
+		}
+	}
+	data, err := readInput(cmd.Key)
+	if err != nil {
+		return err
+	}
+	key, err := certificate.DecodePrivateKey(data, []byte(passphrase))
+	if err != nil {
+		return err
+	}
+	password, err := promptSecret("Bundle password", true)
+	if err != nil {
+		return err
+	}
+	var out []byte
+	if cmd.Format == "jks" {
+		out, err = bundle.JKS(key, cmd.Alias, password)
+	} else {
+		out, err = bundle.PKCS12(key, password)
+	}
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(cmd.Out, out, 0600)
+}
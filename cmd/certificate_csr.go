@@ -0,0 +1,49 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/go-gandi/go-gandi/certificate"
+)
+
+type certificateCSRCmd struct {
+	CN           string   `kong:"arg,help='The certificate CN, Unicode names are converted to punycode'"`
+	AltNames     []string `kong:"name='altname',help='An alternative name of the certificate'"`
+	KeyType      string   `kong:"enum='rsa,ecdsa',default='rsa',help='The type of the private key (rsa, ecdsa)'"`
+	KeySize      int      `kong:"help='The number of bits of RSA keys or the curve size of ECDSA keys'"`
+	KeyOut       string   `kong:"required,help='The file the private key is written to'"`
+	Encrypt      bool     `kong:"help='Encrypt the private key with a passphrase read from a prompt'"`
+	Organization string   `kong:"help='The organization of the subject'"`
+	Country      string   `kong:"help='The country code of the subject'"`
+}
+
+func (cmd *certificateCSRCmd) Run(g *globals) error {
+	var passphrase string
+	if cmd.Encrypt {
+		var err error
+		if passphrase, err = promptSecret("Passphrase", true); err != nil {
+			return err
+		}
+		if passphrase == "" {
+			return fmt.Errorf("The passphrase cannot be empty")
+		}
+	}
+	key, err := certificate.GenerateKey(certificate.KeyType(cmd.KeyType), cmd.KeySize)
+	if err != nil {
+		return err
+	}
+	csr, err := certificate.CreateCSR(key, certificate.CSROptions{
+		CN:           cmd.CN,
+		AltNames:     cmd.AltNames,
+		Organization: cmd.Organization,
+		Country:      cmd.Country,
+	})
+	if err != nil {
+		return err
+	}
+	if err := certificate.WritePrivateKey(cmd.KeyOut, key, []byte(passphrase)); err != nil {
+		return err
+	}
+	fmt.Print(string(csr))
+	return nil
+}
@@ -0,0 +1,29 @@
+package main
+
+import (
+	"context"
+	"log"
+	"os"
+	"time"
+
+	"github.com/go-gandi/go-gandi/certificate/dnsdcv"
+)
+
+type certificateValidateDNSCmd struct {
+	CertificateId string        `kong:"arg,help='The ID of a certificate ordered with the dns validation method'"`
+	Timeout       time.Duration `kong:"default='1h',help='How long to wait for the certificate'"`
+	Interval      time.Duration `kong:"default='30s',help='The interval between two checks of the certificate status'"`
+}
+
+func (cmd *certificateValidateDNSCmd) Run(g *globals) error {
+	validator := dnsdcv.Validator{
+		Certificates: g.certificateHandle,
+		LiveDNS:      g.liveDNSHandle,
+		Interval:     cmd.Interval,
+		Logger:       log.New(os.Stderr, "", log.LstdFlags),
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), cmd.Timeout)
+	defer cancel()
+	result, err := validator.Validate(ctx, cmd.CertificateId)
+	return textPrint(result.Data, err)
+}
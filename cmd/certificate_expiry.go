@@ -0,0 +1,48 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-gandi/go-gandi/certificate/expiry"
+)
+
+type certificateExpiringCmd struct {
+	Within           string `kong:"default='30d',help='The window the certificates expire in, as a number of days such as 30d or a duration such as 72h'"`
+	SkipRedirections bool   `kong:"help='Do not check the certificates of the web redirections of the domains'"`
+}
+
+func (cmd *certificateExpiringCmd) Run(g *globals) error {
+	within, err := parseDays(cmd.Within)
+	if err != nil {
+		return err
+	}
+	scanner := expiry.Scanner{Certificates: g.certificateHandle}
+	if !cmd.SkipRedirections {
+		scanner.Domains = g.domainHandle
+	}
+	expiring, err := scanner.Scan(within)
+	if err = jsonPrint(expiring, err); err != nil {
+		return err
+	}
+	// A non zero exit status lets cron jobs and monitoring alert
+	if len(expiring) > 0 {
+		return fmt.Errorf("%d certificates expire within %s", len(expiring), cmd.Within)
+	}
+	return nil
+}
+
+// parseDays parses a duration which may be given in days with the d
+// suffix, unknown to time.ParseDuration
+func parseDays(s string) (time.Duration, error) {
+	if days := strings.TrimSuffix(s, "d"); days != s {
+		n, err := strconv.Atoi(days)
+		if err != nil || n < 0 {
+			return 0, fmt.Errorf("Invalid number of days '%s'", s)
+		}
+		return time.Duration(n) * 24 * time.Hour, nil
+	}
+	return time.ParseDuration(s)
+}
@@ -0,0 +1,57 @@
+package main
+
+import (
+	"context"
+	"log"
+	"os"
+	"os/signal"
+	"time"
+
+	"github.com/go-gandi/go-gandi/livedns/ddns"
+)
+
+type liveDNSDDNSCmd struct {
+	FQDN      string        `kong:"arg,help='The LiveDNS domain'"`
+	Name      string        `kong:"arg,help='The record name, @ for the apex'"`
+	IPv4      bool          `kong:"name='ipv4',help='Update the A record (the default if no family is given)'"`
+	IPv6      bool          `kong:"name='ipv6',help='Update the AAAA record'"`
+	Interface string        `kong:"help='Read the addresses of this network interface instead of asking a web service'"`
+	URL       string        `kong:"name='url',help='A web service returning the address of the client as plain text'"`
+	TTL       int           `kong:"default='300',help='The TTL of the records'"`
+	Interval  time.Duration `kong:"help='Update the records periodically with this interval instead of once'"`
+	Jitter    time.Duration `kong:"help='The maximum random delay added to the interval'"`
+}
+
+func (d *liveDNSDDNSCmd) Run(g *globals) error {
+	updater := ddns.Updater{
+		Client:   g.liveDNSHandle,
+		Domain:   d.FQDN,
+		Name:     d.Name,
+		TTL:      d.TTL,
+		Interval: d.Interval,
+		Jitter:   d.Jitter,
+	}
+	if d.IPv4 || !d.IPv6 {
+		updater.Families = append(updater.Families, ddns.IPv4)
+	}
+	if d.IPv6 {
+		updater.Families = append(updater.Families, ddns.IPv6)
+	}
+	switch {
+	case d.Interface != "":
+		updater.Source = &ddns.InterfaceSource{Name: d.Interface}
+	case d.URL != "":
+		updater.Source = &ddns.HTTPSource{URL: d.URL}
+	}
+	if d.Interval == 0 {
+		return jsonPrint(updater.Update(context.Background()))
+	}
+	updater.Logger = log.New(os.Stderr, "", log.LstdFlags)
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+	err := updater.Run(ctx)
+	if err == context.Canceled {
+		return nil
+	}
+	return err
+}
@@ -0,0 +1,104 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/go-gandi/go-gandi/email"
+	"github.com/go-gandi/go-gandi/email/maildns"
+)
+
+type emailCmd struct {
+	Mailbox emailMailboxCmd `kong:"cmd,help='Manage mailboxes'"`
+	Forward emailForwardCmd `kong:"cmd,help='Manage forwardings'"`
+	Import  emailImportCmd  `kong:"cmd,help='Create mailboxes and their forwardings from a CSV or YAML file'"`
+	DNS     emailDNSCmd     `kong:"cmd,name='dns',help='Check the DNS records required by Gandi Mail in LiveDNS, and create them with --apply'"`
+}
+
+type emailDNSCmd struct {
+	Domain      string `kong:"arg,help='The LiveDNS domain of the mailboxes'"`
+	Apply       bool   `kong:"help='Create the missing records and merge the SPF policy'"`
+	Force       bool   `kong:"help='Replace the conflicting records when applying'"`
+	DMARCPolicy string `kong:"name='dmarc-policy',default='none',help='The policy of the created DMARC record'"`
+	DMARCReport string `kong:"name='dmarc-report',help='The address receiving the DMARC reports'"`
+}
+
+func (d *emailDNSCmd) Run(g *globals) error {
+	opts := maildns.Options{DMARCPolicy: d.DMARCPolicy, DMARCReport: d.DMARCReport, Force: d.Force}
+	if d.Apply {
+		return jsonPrint(maildns.Apply(g.liveDNSHandle, d.Domain, opts))
+	}
+	return jsonPrint(maildns.Verify(g.liveDNSHandle, d.Domain, opts))
+}
+
+type emailImportCmd struct {
+	Domain         string `kong:"arg,help='The domain of the mailboxes'"`
+	File           string `kong:"arg,help='The CSV or YAML file describing the mailboxes, - for stdin'"`
+	Format         string `kong:"enum=',csv,yaml',default='',help='The format of the file, guessed from its extension if not set (csv, yaml)'"`
+	Concurrency    int    `kong:"default='4',help='The maximum number of mailboxes created at the same time'"`
+	PasswordLength int    `kong:"default='20',help='The length of the generated passwords'"`
+	Report         string `kong:"help='Write the results as CSV to this file, including the generated passwords'"`
+	// CheckSlot needs the permission to read the slots
+	CheckSlot bool `kong:"help='Check the domain has free slots for the mailboxes before creating them'"`
+}
+
+func (d *emailImportCmd) Run(g *globals) error {
+	text, err := readInput(d.File)
+	if err != nil {
+		return err
+	}
+	format := d.Format
+	if format == "" {
+		switch strings.ToLower(filepath.Ext(d.File)) {
+		case ".yaml", ".yml":
+			format = "yaml"
+		default:
+			format = "csv"
+		}
+	}
+	var specs []email.MailboxSpec
+	if format == "yaml" {
+		specs, err = email.ParseMailboxesYAML(bytes.NewReader(text))
+	} else {
+		specs, err = email.ParseMailboxesCSV(bytes.NewReader(text))
+	}
+	if err != nil {
+		return err
+	}
+	results, err := g.emailHandle.ProvisionMailboxes(d.Domain, specs, email.ProvisionOptions{
+		Concurrency:    d.Concurrency,
+		PasswordLength: d.PasswordLength,
+		CheckSlots:     d.CheckSlot,
+	})
+	if err != nil {
+		return err
+	}
+	if d.Report != "" {
+		f, err := os.OpenFile(d.Report, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0600)
+		if err != nil {
+			return err
+		}
+		if err := email.WriteProvisionReport(f, results); err != nil {
+			f.Close()
+			return err
+		}
+		if err := f.Close(); err != nil {
+			return err
+		}
+	} else if err := email.WriteProvisionReport(os.Stdout, results); err != nil {
+		return err
+	}
+	failed := 0
+	for _, r := range results {
+		if r.Error != "" {
+			failed++
+		}
+	}
+	if failed > 0 {
+		return fmt.Errorf("%d of the %d mailboxes failed", failed, len(results))
+	}
+	return nil
+}
@@ -10,6 +10,7 @@ import (
 	"github.com/go-gandi/go-gandi/certificate"
 	"github.com/go-gandi/go-gandi/config"
 	"github.com/go-gandi/go-gandi/domain"
+	"github.com/go-gandi/go-gandi/email"
 	"github.com/go-gandi/go-gandi/livedns"
 	"github.com/go-gandi/go-gandi/simplehosting"
 )
@@ -20,6 +21,7 @@ type cli struct {
 	Domain              domainCmd        `kong:"cmd,help='Manage Domains'"`
 	SimpleHosting       simpleHostingCmd `kong:"cmd,help='Manage Simple Hosting'"`
 	Certificate         certificateCmd   `kong:"cmd,help='Manage Simple Hosting'"`
+	Email               emailCmd         `kong:"cmd,help='Manage Email'"`
 	Debug               bool             `kong:"short='d',help='Enable debug logging'"`
 	DryRun              bool             `kong:"help='Enable dry run mode'"`
 	APIKey              string           `kong:"env='GANDI_KEY',help='The deprecated Gandi API Key (may be stored in the GANDI_KEY environment variable)'"`
@@ -33,6 +35,7 @@ type globals struct {
 	domainHandle        *domain.Domain
 	simpleHostingHandle *simplehosting.SimpleHosting
 	certificateHandle   *certificate.Certificate
+	emailHandle         *email.Email
 	Version             versionFlag `kong:"name='version',help='Print version information and quit'"`
 }
 
@@ -67,6 +70,7 @@ func main() {
 	c.globals.liveDNSHandle = gandi.NewLiveDNSClient(g)
 	c.globals.simpleHostingHandle = gandi.NewSimpleHostingClient(g)
 	c.globals.certificateHandle = gandi.NewCertificateClient(g)
+	c.globals.emailHandle = gandi.NewEmailClient(g)
 	err := ctx.Run(&c.globals)
 	ctx.FatalIfErrorf(err)
 }
@@ -80,7 +84,7 @@ func jsonPrint(data interface{}, err error) error {
 	return nil
 }
 
-func textPrint(data []byte, err error) error { //nolint
+func textPrint(data []byte, err error) error {
 	if err != nil {
 		return fmt.Errorf("Error: %w", err)
 	}
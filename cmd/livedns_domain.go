@@ -1,21 +1,73 @@
 package main
 
+import (
+	"bytes"
+	"fmt"
+
+	"github.com/go-gandi/go-gandi/livedns"
+	"github.com/go-gandi/go-gandi/livedns/zonefile"
+)
+
 type liveDNSCmd struct {
-	Create liveDNSCreateCmd `kong:"cmd,help='Enable LiveDNS for a Domain'"`
-	List   liveDNSListCmd   `kong:"cmd,help='List LiveDNS Domains'"`
-	Manage liveDNSManageCmd `kong:"cmd,help='Manage LiveDNS Domain'"`
+	Create      liveDNSCreateCmd          `kong:"cmd,help='Enable LiveDNS for a Domain'"`
+	Delete      liveDNSDeleteCmd          `kong:"cmd,help='Remove a Domain from LiveDNS'"`
+	List        liveDNSListCmd            `kong:"cmd,help='List LiveDNS Domains'"`
+	RecordTypes liveDNSRecordTypesCmd     `kong:"cmd,name='rrtypes',help='List the record types supported by LiveDNS'"`
+	TSIGKeys    liveDNSListTSIGKeysCmd    `kong:"cmd,name='tsig-keys',help='List the TSIG keys of the account'"`
+	CreateTSIG  liveDNSCreateTSIGKeyCmd   `kong:"cmd,name='create-tsig',help='Create a TSIG key'"`
+	DDNS        liveDNSDDNSCmd            `kong:"cmd,name='ddns',help='Update A and AAAA records with the current addresses of the host'"`
+	Secondary   liveDNSSecondaryConfigCmd `kong:"cmd,name='secondary-config',help='Generate the configuration of a secondary nameserver for several zones'"`
+	RFC2136     liveDNSRFC2136Cmd         `kong:"cmd,name='rfc2136-gateway',help='Serve RFC 2136 dynamic updates and apply them to LiveDNS'"`
+	Manage      liveDNSManageCmd          `kong:"cmd,help='Manage LiveDNS Domain'"`
 }
 
 type liveDNSCreateCmd struct {
-	FQDN string `kong:"arg"`
-	TTL  int    `kong:"arg,optional,default='60',help='The default TTL of the domain, default= 60'"`
+	FQDN  string `kong:"arg"`
+	TTL   int    `kong:"arg,optional,default='60',help='The default TTL of the domain, default= 60'"`
+	Clone string `kong:"help='A LiveDNS domain whose records are copied to the new domain'"`
+	File  string `kong:"help='A zone file containing the initial records of the domain'"`
 }
 
 func (d *liveDNSCreateCmd) Run(g *globals) error {
 	l := g.liveDNSHandle
+	switch {
+	case d.Clone != "" && d.File != "":
+		return fmt.Errorf("--clone and --file cannot be used together")
+	case d.Clone != "":
+		return jsonPrint(l.CloneDomain(d.FQDN, d.Clone, d.TTL))
+	case d.File != "":
+		text, err := readInput(d.File)
+		if err != nil {
+			return err
+		}
+		records, err := zonefile.Parse(bytes.NewReader(text), d.FQDN)
+		if err != nil {
+			return err
+		}
+		if err := livedns.ValidateRecords(records); err != nil {
+			return err
+		}
+		return jsonPrint(l.CreateDomainWithRecords(d.FQDN, d.TTL, records))
+	}
 	return jsonPrint(l.CreateDomain(d.FQDN, d.TTL))
 }
 
+type liveDNSDeleteCmd struct {
+	FQDN string `kong:"arg"`
+}
+
+func (d *liveDNSDeleteCmd) Run(g *globals) error {
+	l := g.liveDNSHandle
+	return noPrint(l.DeleteDomain(d.FQDN))
+}
+
+type liveDNSRecordTypesCmd struct{}
+
+func (d *liveDNSRecordTypesCmd) Run(g *globals) error {
+	l := g.liveDNSHandle
+	return jsonPrint(l.ListRecordTypes())
+}
+
 type liveDNSListCmd struct{}
 
 func (c *liveDNSListCmd) Run(g *globals) error {
@@ -28,24 +80,31 @@ type liveDNSManageCmd struct {
 		Name    string              `kong:"arg"`
 		Display liveDNSGetDomainCmd `kong:"cmd,help='Display the domain'"`
 		Records struct {
-			List   liveDNSGetRecordsCmd   `kong:"cmd,name='list',help='Display records for domain'"`
-			Create liveDNSCreateRecordCmd `kong:"cmd,name='create',help='Create records for domain'"`
-			Update liveDNSUpdateRecordCmd `kong:"cmd,name='update',help='Update records for domain'"`
-			Delete liveDNSDeleteRecordCmd `kong:"cmd,name='delete',help='Delete records for domain'"`
+			List   liveDNSGetRecordsCmd    `kong:"cmd,name='list',help='Display records for domain'"`
+			Create liveDNSCreateRecordCmd  `kong:"cmd,name='create',help='Create records for domain'"`
+			Update liveDNSUpdateRecordCmd  `kong:"cmd,name='update',help='Update records for domain'"`
+			Delete liveDNSDeleteRecordCmd  `kong:"cmd,name='delete',help='Delete records for domain'"`
+			Import liveDNSImportRecordsCmd `kong:"cmd,name='import',help='Import records for domain from a zone file'"`
+			Sync   liveDNSSyncRecordsCmd   `kong:"cmd,name='sync',help='Apply the minimal changes to get the records of a zone file'"`
+			Wait   liveDNSWaitRecordCmd    `kong:"cmd,name='wait',help='Wait until the nameservers of the domain serve the current values of a record'"`
 		} `kong:"cmd"`
-		Sign          liveDNSSignDomainCmd           `kong:"cmd,help='Sign the domain'"`
-		Keys          liveDNSGetDomainKeysCmd        `kong:"cmd,help='Get the DNSSEC keys for the domain'"`
-		DeleteKey     liveDNSDeleteDomainKeyCmd      `kong:"cmd,help='Delete a DNSSEC key for the domain'"`
-		NameServers   liveDNSGetDomainNSCmd          `kong:"cmd,help='Get nameservers for the domain'"`
-		Snapshot      liveDNSCreateSnapshotCmd       `kong:"cmd,help='Create a snapshot of the domain'"`
-		GetSnapshot   liveDNSGetSnapshotCmd          `kong:"cmd,name='get-snapshot',help='Get a snapshot of the domain'"`
-		ListSnapshots liveDNSListSnapshotsCmd        `kong:"cmd,name='list-snapshots',help='List snapshots of the domain'"`
-		GetTsigs      liveDNSGetTSIGsCmd             `kong:"cmd,name='get-tsigs',help='Get TSIGs'"`
-		AddTSIG       liveDNSAddTSIGToDomainCmd      `kong:"cmd,name='add-tsig',help='Add TSIG to domain'"`
-		RemoveTSIG    liveDNSRemoveTSIGFromDomainCmd `kong:"cmd,name='remove-tsig',help='Remove TSIG from domain'"`
-		GetAXFRs      liveDNSListAXFRSlavesCmd       `kong:"cmd,name='get-axfrs',help='Get AXFRs'"`
-		AddAXFR       liveDNSAddAXFRSlaveCmd         `kong:"cmd,name='add-axfr',help='Add AXFR to domain'"`
-		RemoveAXFR    liveDNSRemoveAXFRSlaveCmd      `kong:"cmd,name='remove-axfr',help='Remove AXFR from domain'"`
+		Sign          liveDNSSignDomainCmd            `kong:"cmd,help='Sign the domain'"`
+		Keys          liveDNSGetDomainKeysCmd         `kong:"cmd,help='Get the DNSSEC keys for the domain'"`
+		DeleteKey     liveDNSDeleteDomainKeyCmd       `kong:"cmd,help='Delete a DNSSEC key for the domain'"`
+		NameServers   liveDNSGetDomainNSCmd           `kong:"cmd,help='Get nameservers for the domain'"`
+		DNSSEC        liveDNSGetDNSSECAvailabilityCmd `kong:"cmd,name='dnssec-available',help='Tell if DNSSEC can be enabled for the domain'"`
+		Snapshot      liveDNSCreateSnapshotCmd        `kong:"cmd,help='Create a snapshot of the domain'"`
+		GetSnapshot   liveDNSGetSnapshotCmd           `kong:"cmd,name='get-snapshot',help='Get a snapshot of the domain'"`
+		ListSnapshots liveDNSListSnapshotsCmd         `kong:"cmd,name='list-snapshots',help='List snapshots of the domain'"`
+		RestoreSnap   liveDNSRestoreSnapshotCmd       `kong:"cmd,name='restore-snapshot',help='Restore the records of a snapshot of the domain'"`
+		PruneSnaps    liveDNSPruneSnapshotsCmd        `kong:"cmd,name='prune-snapshots',help='Delete the snapshots not retained by a retention policy (only list them with --dry-run)'"`
+		DiffSnapshots liveDNSDiffSnapshotsCmd         `kong:"cmd,name='diff-snapshots',help='Show the differences between two snapshots, or a snapshot and the current records'"`
+		GetTsigs      liveDNSGetTSIGsCmd              `kong:"cmd,name='get-tsigs',help='Get TSIGs'"`
+		AddTSIG       liveDNSAddTSIGToDomainCmd       `kong:"cmd,name='add-tsig',help='Add TSIG to domain'"`
+		RemoveTSIG    liveDNSRemoveTSIGFromDomainCmd  `kong:"cmd,name='remove-tsig',help='Remove TSIG from domain'"`
+		GetAXFRs      liveDNSListAXFRSlavesCmd        `kong:"cmd,name='get-axfrs',help='Get AXFRs'"`
+		AddAXFR       liveDNSAddAXFRSlaveCmd          `kong:"cmd,name='add-axfr',help='Add AXFR to domain'"`
+		RemoveAXFR    liveDNSRemoveAXFRSlaveCmd       `kong:"cmd,name='remove-axfr',help='Remove AXFR from domain'"`
 	} `kong:"arg"`
 }
 
@@ -90,3 +149,11 @@ func (d *liveDNSGetDomainNSCmd) Run(g *globals) error {
 	l := g.liveDNSHandle
 	return jsonPrint(l.GetDomainNS(fqdn))
 }
+
+type liveDNSGetDNSSECAvailabilityCmd struct{}
+
+func (d *liveDNSGetDNSSECAvailabilityCmd) Run(g *globals) error {
+	fqdn := c.LiveDNS.Manage.Name.Name
+	l := g.liveDNSHandle
+	return jsonPrint(l.GetDNSSECAvailability(fqdn))
+}
@@ -0,0 +1,246 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strings"
+
+	"golang.org/x/term"
+
+	"github.com/go-gandi/go-gandi/email"
+)
+
+type emailMailboxCmd struct {
+	List   emailListMailboxesCmd `kong:"cmd,help='List the mailboxes of a domain'"`
+	Get    emailGetMailboxCmd    `kong:"cmd,help='Display a mailbox'"`
+	Create emailCreateMailboxCmd `kong:"cmd,help='Create a mailbox'"`
+	Update emailUpdateMailboxCmd `kong:"cmd,help='Update the login, password or aliases of a mailbox'"`
+	Delete emailDeleteMailboxCmd `kong:"cmd,help='Delete a mailbox'"`
+}
+
+type emailForwardCmd struct {
+	List   emailListForwardsCmd  `kong:"cmd,help='List the forwardings of a domain'"`
+	Create emailCreateForwardCmd `kong:"cmd,help='Create a forwarding'"`
+	Update emailUpdateForwardCmd `kong:"cmd,help='Replace the destinations of a forwarding'"`
+	Delete emailDeleteForwardCmd `kong:"cmd,help='Delete a forwarding'"`
+}
+
+// passwordFlags selects how the password of a mailbox is read. It is
+// never given as an argument, since arguments are visible to the other
+// users of the host.
+type passwordFlags struct {
+	PasswordStdin    bool `kong:"help='Read the password from the first line of the standard input'"`
+	GeneratePassword bool `kong:"help='Generate a password meeting the Gandi policy and print it'"`
+}
+
+// read returns the password, prompting for it on the terminal when
+// no flag is set, and whether it has been generated
+func (p passwordFlags) read() (string, bool, error) {
+	switch {
+	case p.PasswordStdin && p.GeneratePassword:
+		return "", false, fmt.Errorf("--password-stdin and --generate-password cannot be used together")
+	case p.GeneratePassword:
+		password, err := email.GeneratePassword(0)
+		return password, true, err
+	case p.PasswordStdin:
+		line, err := bufio.NewReader(os.Stdin).ReadString('\n')
+		if err != nil && line == "" {
+			return "", false, fmt.Errorf("Fail to read the password from stdin (error '%w')", err)
+		}
+		return strings.TrimRight(line, "\r\n"), false, nil
+	}
+	if !term.IsTerminal(int(os.Stdin.Fd())) {
+		return "", false, fmt.Errorf("No terminal to prompt for the password, use --password-stdin or --generate-password")
+	}
+	password, err := promptSecret("Password", true)
+	return password, false, err
+}
+
+// promptSecret reads a secret on the terminal without echoing it. The
+// secret is read twice when confirm is set, for new secrets.
+func promptSecret(label string, confirm bool) (string, error) {
+	fd := int(os.Stdin.Fd())
+	if !term.IsTerminal(fd) {
+		return "", fmt.Errorf("No terminal to prompt for the %s", strings.ToLower(label))
+	}
+	fmt.Fprintf(os.Stderr, "%s: ", label)
+	secret, err := term.ReadPassword(fd)
+	fmt.Fprintln(os.Stderr)
+	if err != nil || !confirm {
+		return string(secret), err
+	}
+	fmt.Fprintf(os.Stderr, "Confirm %s: ", strings.ToLower(label))
+	again, err := term.ReadPassword(fd)
+	fmt.Fprintln(os.Stderr)
+	if err != nil {
+		return "", err
+	}
+	if string(secret) != string(again) {
+		return "", fmt.Errorf("The %ss do not match", strings.ToLower(label))
+	}
+	return string(secret), nil
+}
+
+type generatedPassword struct {
+	Login    string `json:"login"`
+	Address  string `json:"address"`
+	Password string `json:"password"`
+}
+
+type emailListMailboxesCmd struct {
+	Domain string `kong:"arg,help='The domain of the mailboxes'"`
+}
+
+func (d *emailListMailboxesCmd) Run(g *globals) error {
+	return jsonPrint(g.emailHandle.ListMailboxes(d.Domain))
+}
+
+type emailGetMailboxCmd struct {
+	Domain    string `kong:"arg,help='The domain of the mailbox'"`
+	MailboxID string `kong:"arg,help='The ID of the mailbox'"`
+}
+
+func (d *emailGetMailboxCmd) Run(g *globals) error {
+	return jsonPrint(g.emailHandle.GetMailbox(d.Domain, d.MailboxID))
+}
+
+type emailCreateMailboxCmd struct {
+	Domain  string   `kong:"arg,help='The domain of the mailbox'"`
+	Login   string   `kong:"arg,help='The login of the mailbox, without the domain'"`
+	Type    string   `kong:"enum='standard,premium',default='standard',help='The type of the mailbox (standard, premium)'"`
+	Aliases []string `kong:"name='alias',help='An alias of the mailbox'"`
+	// CheckSlot needs the permission to read the slots
+	CheckSlot bool `kong:"help='Check the domain has a free slot for the mailbox type before creating it'"`
+	passwordFlags
+}
+
+func (d *emailCreateMailboxCmd) Run(g *globals) error {
+	password, generated, err := d.read()
+	if err != nil {
+		return err
+	}
+	if err := email.ValidatePassword(password); err != nil {
+		return err
+	}
+	create := g.emailHandle.CreateEmail
+	if d.CheckSlot {
+		create = g.emailHandle.CreateEmailChecked
+	}
+	err = create(d.Domain, email.CreateEmailRequest{
+		Login:       d.Login,
+		MailboxType: d.Type,
+		Password:    password,
+		Aliases:     d.Aliases,
+	})
+	if generated {
+		return jsonPrint(generatedPassword{Login: d.Login, Address: d.Login + "@" + d.Domain, Password: password}, err)
+	}
+	return noPrint(err)
+}
+
+type emailUpdateMailboxCmd struct {
+	Domain    string   `kong:"arg,help='The domain of the mailbox'"`
+	MailboxID string   `kong:"arg,help='The ID of the mailbox'"`
+	Login     string   `kong:"help='The new login of the mailbox'"`
+	Password  bool     `kong:"help='Change the password, read from a prompt unless --password-stdin or --generate-password is set'"`
+	Aliases   []string `kong:"name='alias',help='Replace the aliases of the mailbox'"`
+	NoAliases bool     `kong:"help='Remove all the aliases of the mailbox'"`
+	passwordFlags
+}
+
+func (d *emailUpdateMailboxCmd) Run(g *globals) error {
+	var patch email.MailboxPatch
+	if d.Login != "" {
+		patch.Login = &d.Login
+	}
+	switch {
+	case d.NoAliases && len(d.Aliases) > 0:
+		return fmt.Errorf("--alias and --no-aliases cannot be used together")
+	case d.NoAliases:
+		patch.Aliases = &[]string{}
+	case len(d.Aliases) > 0:
+		patch.Aliases = &d.Aliases
+	}
+	var password string
+	var generated bool
+	if d.Password || d.PasswordStdin || d.GeneratePassword {
+		var err error
+		if password, generated, err = d.read(); err != nil {
+			return err
+		}
+		if err := email.ValidatePassword(password); err != nil {
+			return err
+		}
+		patch.Password = &password
+	}
+	if patch == (email.MailboxPatch{}) {
+		return fmt.Errorf("Nothing to update")
+	}
+	login := d.Login
+	if generated && login == "" {
+		// The mailbox is read before the update so that the
+		// generated password is not lost if it cannot be read
+		mailbox, err := g.emailHandle.GetMailbox(d.Domain, d.MailboxID)
+		if err != nil {
+			return fmt.Errorf("Fail to get the mailbox %s (error '%w')", d.MailboxID, err)
+		}
+		login = mailbox.Login
+	}
+	err := g.emailHandle.PatchMailbox(d.Domain, d.MailboxID, patch)
+	if generated {
+		return jsonPrint(generatedPassword{Login: login, Address: login + "@" + d.Domain, Password: password}, err)
+	}
+	return noPrint(err)
+}
+
+type emailDeleteMailboxCmd struct {
+	Domain    string `kong:"arg,help='The domain of the mailbox'"`
+	MailboxID string `kong:"arg,help='The ID of the mailbox'"`
+}
+
+func (d *emailDeleteMailboxCmd) Run(g *globals) error {
+	return noPrint(g.emailHandle.DeleteEmail(d.Domain, d.MailboxID))
+}
+
+type emailListForwardsCmd struct {
+	Domain string `kong:"arg,help='The domain of the forwardings'"`
+}
+
+func (d *emailListForwardsCmd) Run(g *globals) error {
+	return jsonPrint(g.emailHandle.GetForwards(d.Domain))
+}
+
+type emailCreateForwardCmd struct {
+	Domain       string   `kong:"arg,help='The domain of the forwarding'"`
+	Source       string   `kong:"arg,help='The forwarded address, without the domain, or * for the catch-all'"`
+	Destinations []string `kong:"arg,help='The addresses the messages are forwarded to'"`
+}
+
+func (d *emailCreateForwardCmd) Run(g *globals) error {
+	return noPrint(g.emailHandle.CreateForward(d.Domain, email.CreateForwardRequest{
+		Source:       d.Source,
+		Destinations: d.Destinations,
+	}))
+}
+
+type emailUpdateForwardCmd struct {
+	Domain       string   `kong:"arg,help='The domain of the forwarding'"`
+	Source       string   `kong:"arg,help='The forwarded address, without the domain'"`
+	Destinations []string `kong:"arg,help='The addresses the messages are forwarded to'"`
+}
+
+func (d *emailUpdateForwardCmd) Run(g *globals) error {
+	return noPrint(g.emailHandle.UpdateForward(d.Domain, d.Source, email.UpdateForwardRequest{
+		Destinations: d.Destinations,
+	}))
+}
+
+type emailDeleteForwardCmd struct {
+	Domain string `kong:"arg,help='The domain of the forwarding'"`
+	Source string `kong:"arg,help='The forwarded address, without the domain'"`
+}
+
+func (d *emailDeleteForwardCmd) Run(g *globals) error {
+	return noPrint(g.emailHandle.DeleteForward(d.Domain, d.Source))
+}
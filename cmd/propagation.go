@@ -0,0 +1,41 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/go-gandi/go-gandi/livedns"
+	"github.com/go-gandi/go-gandi/livedns/propagation"
+)
+
+type liveDNSWaitRecordCmd struct {
+	Name    string        `kong:"arg,help='The name of the record'"`
+	Type    string        `kong:"arg,help='The type of the record'"`
+	Deleted bool          `kong:"help='Wait until the record is no longer served'"`
+	Timeout time.Duration `kong:"default='10m',help='How long to wait for the nameservers'"`
+	IPv4    bool          `kong:"name='ipv4',help='Only query the nameservers over IPv4'"`
+}
+
+func (d *liveDNSWaitRecordCmd) Run(g *globals) error {
+	fqdn := c.LiveDNS.Manage.Name.Name
+	l := g.liveDNSHandle
+	expected := livedns.DomainRecord{RrsetName: d.Name, RrsetType: d.Type}
+	if !d.Deleted {
+		var err error
+		expected, err = l.GetDomainRecordByNameAndType(fqdn, d.Name, d.Type)
+		if err != nil {
+			return err
+		}
+	}
+	resolver := &propagation.NetResolver{}
+	if d.IPv4 {
+		resolver.Network = "ip4"
+	}
+	checker := propagation.Checker{Client: l, Resolver: resolver}
+	statuses, err := checker.Wait(context.Background(), fqdn, expected, d.Timeout)
+	for _, status := range statuses {
+		fmt.Println(status)
+	}
+	return err
+}
@@ -0,0 +1,51 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"os/signal"
+	"strings"
+
+	"github.com/go-gandi/go-gandi/livedns/rfc2136"
+)
+
+type liveDNSRFC2136Cmd struct {
+	Listen        string   `kong:"default=':53',help='The address the gateway listens on, over UDP and TCP'"`
+	Zones         []string `kong:"name='zone',required,help='A zone accepting updates, as zone or zone=domain when the LiveDNS domain differs'"`
+	TSIG          []string `kong:"name='tsig',help='A TSIG key accepted by the gateway, as name:base64-secret'"`
+	AllowUnsigned bool     `kong:"help='Accept updates without TSIG signature'"`
+}
+
+func (d *liveDNSRFC2136Cmd) Run(g *globals) error {
+	zones := map[string]string{}
+	for _, zone := range d.Zones {
+		domain := zone
+		if i := strings.Index(zone, "="); i >= 0 {
+			zone, domain = zone[:i], zone[i+1:]
+		}
+		zones[zone] = domain
+	}
+	secrets := map[string]string{}
+	for _, key := range d.TSIG {
+		i := strings.Index(key, ":")
+		if i <= 0 {
+			return fmt.Errorf("Invalid TSIG key '%s', expected name:secret", key)
+		}
+		secrets[key[:i]] = key[i+1:]
+	}
+	if len(secrets) == 0 && !d.AllowUnsigned {
+		return fmt.Errorf("At least one TSIG key is required, unless --allow-unsigned is set")
+	}
+	gateway := rfc2136.NewGateway(g.liveDNSHandle, zones, secrets)
+	gateway.AllowUnsigned = d.AllowUnsigned
+	gateway.Logger = log.New(os.Stderr, "", log.LstdFlags)
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+	err := gateway.ListenAndServe(ctx, d.Listen)
+	if err == context.Canceled {
+		return nil
+	}
+	return err
+}
@@ -1,5 +1,12 @@
 package main
 
+import (
+	"fmt"
+	"time"
+
+	"github.com/go-gandi/go-gandi/livedns"
+)
+
 type liveDNSListSnapshotsCmd struct{}
 
 func (d *liveDNSListSnapshotsCmd) Run(g *globals) error {
@@ -8,11 +15,16 @@ func (d *liveDNSListSnapshotsCmd) Run(g *globals) error {
 	return jsonPrint(l.ListSnapshots(fqdn))
 }
 
-type liveDNSCreateSnapshotCmd struct{}
+type liveDNSCreateSnapshotCmd struct {
+	Name string `kong:"arg,optional,help='The name of the snapshot'"`
+}
 
 func (d *liveDNSCreateSnapshotCmd) Run(g *globals) error {
 	fqdn := c.LiveDNS.Manage.Name.Name
 	l := g.liveDNSHandle
+	if d.Name != "" {
+		return jsonPrint(l.CreateNamedSnapshot(fqdn, d.Name))
+	}
 	return jsonPrint(l.CreateSnapshot(fqdn))
 }
 
@@ -25,3 +37,70 @@ func (d *liveDNSGetSnapshotCmd) Run(g *globals) error {
 	l := g.liveDNSHandle
 	return jsonPrint(l.GetSnapshot(fqdn, d.ID))
 }
+
+type liveDNSRestoreSnapshotCmd struct {
+	ID string `kong:"arg,help='The ID of the snapshot to restore'"`
+}
+
+func (d *liveDNSRestoreSnapshotCmd) Run(g *globals) error {
+	fqdn := c.LiveDNS.Manage.Name.Name
+	l := g.liveDNSHandle
+	if c.DryRun {
+		changes, err := l.DiffSnapshotWithZone(fqdn, d.ID)
+		if err != nil {
+			return err
+		}
+		printChanges(changes)
+		return nil
+	}
+	return jsonPrint(l.RestoreSnapshot(fqdn, d.ID))
+}
+
+type liveDNSDiffSnapshotsCmd struct {
+	From string `kong:"arg,help='The ID of the snapshot to compare'"`
+	To   string `kong:"arg,optional,help='The ID of the snapshot to compare with. Without it, show the changes a restore of the snapshot would apply.'"`
+}
+
+func (d *liveDNSDiffSnapshotsCmd) Run(g *globals) error {
+	fqdn := c.LiveDNS.Manage.Name.Name
+	l := g.liveDNSHandle
+	var (
+		changes []livedns.Change
+		err     error
+	)
+	if d.To == "" {
+		changes, err = l.DiffSnapshotWithZone(fqdn, d.From)
+	} else {
+		changes, err = l.DiffSnapshots(fqdn, d.From, d.To)
+	}
+	if err != nil {
+		return err
+	}
+	printChanges(changes)
+	return nil
+}
+
+func printChanges(changes []livedns.Change) {
+	if len(changes) == 0 {
+		fmt.Println("No changes")
+	}
+	for _, change := range changes {
+		fmt.Println(change)
+	}
+}
+
+type liveDNSPruneSnapshotsCmd struct {
+	KeepLast         int           `kong:"help='The number of most recent snapshots to keep'"`
+	KeepWithin       time.Duration `kong:"help='Keep the snapshots created during this duration (for instance 720h)'"`
+	IncludeAutomatic bool          `kong:"help='Also prune automatic snapshots'"`
+}
+
+func (d *liveDNSPruneSnapshotsCmd) Run(g *globals) error {
+	fqdn := c.LiveDNS.Manage.Name.Name
+	l := g.liveDNSHandle
+	return jsonPrint(l.PruneSnapshots(fqdn, livedns.RetentionPolicy{
+		KeepLast:         d.KeepLast,
+		KeepWithin:       d.KeepWithin,
+		IncludeAutomatic: d.IncludeAutomatic,
+	}))
+}
@@ -0,0 +1,61 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/go-gandi/go-gandi/livedns"
+	"github.com/go-gandi/go-gandi/livedns/zonefile"
+)
+
+type liveDNSImportRecordsCmd struct {
+	File string `kong:"arg,help='The zone file to import, - to read it from stdin'"`
+	Mode string `kong:"enum='replace,merge',default='replace',help='Replace all the records of the zone or merge the zone file with them (replace, merge)'"`
+}
+
+func (d *liveDNSImportRecordsCmd) Run(g *globals) error {
+	fqdn := c.LiveDNS.Manage.Name.Name
+	l := g.liveDNSHandle
+	text, err := readInput(d.File)
+	if err != nil {
+		return err
+	}
+	mode := livedns.ImportReplace
+	if d.Mode == "merge" {
+		mode = livedns.ImportMerge
+	}
+	if c.DryRun {
+		// The API only validates the zone file in dry run
+		// mode: show the zone as it would be after the import
+		// on stderr, keeping the JSON response alone on stdout.
+		if err := previewImport(l, fqdn, text, mode); err != nil {
+			return err
+		}
+	}
+	return jsonPrint(l.ImportZoneText(fqdn, text, mode))
+}
+
+func previewImport(l *livedns.LiveDNS, fqdn string, text []byte, mode livedns.ImportMode) error {
+	records, err := zonefile.Parse(bytes.NewReader(text), fqdn)
+	if err != nil {
+		return err
+	}
+	if mode == livedns.ImportMerge {
+		current, err := l.GetDomainRecords(fqdn)
+		if err != nil {
+			return fmt.Errorf("Fail to get the current records (error '%w')", err)
+		}
+		records = zonefile.Merge(current, records)
+	}
+	return zonefile.Write(os.Stderr, fqdn, records)
+}
+
+// readInput reads a file, or stdin if the filename is "-"
+func readInput(filename string) ([]byte, error) {
+	if filename == "-" {
+		return io.ReadAll(os.Stdin)
+	}
+	return os.ReadFile(filename)
+}
@@ -1,5 +1,7 @@
 package main
 
+import "github.com/go-gandi/go-gandi/livedns/rdata"
+
 type liveDNSGetRecordsCmd struct {
 	Name string `kong:"arg,optional,name='name',help='The name of the record to fetch'"`
 	Type string `kong:"arg,optional,help='The type of the record to retrieve. You must specify the name too.'"`
@@ -27,6 +29,9 @@ type liveDNSCreateRecordCmd struct {
 func (d *liveDNSCreateRecordCmd) Run(g *globals) error {
 	fqdn := c.LiveDNS.Manage.Name.Name
 	l := g.liveDNSHandle
+	if err := rdata.Validate(d.Type, d.Values); err != nil {
+		return err
+	}
 	return jsonPrint(l.CreateDomainRecord(fqdn, d.Name, d.Type, d.TTL, d.Values))
 }
 
@@ -40,6 +45,9 @@ type liveDNSUpdateRecordCmd struct {
 func (d *liveDNSUpdateRecordCmd) Run(g *globals) error {
 	fqdn := c.LiveDNS.Manage.Name.Name
 	l := g.liveDNSHandle
+	if err := rdata.Validate(d.Type, d.Values); err != nil {
+		return err
+	}
 	return jsonPrint(l.UpdateDomainRecordByNameAndType(fqdn, d.Name, d.Type, d.TTL, d.Values))
 }
 
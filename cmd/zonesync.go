@@ -0,0 +1,42 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+
+	"github.com/go-gandi/go-gandi/livedns"
+	"github.com/go-gandi/go-gandi/livedns/zonefile"
+)
+
+type liveDNSSyncRecordsCmd struct {
+	File                string `kong:"arg,help='The zone file describing the desired records, - to read it from stdin'"`
+	MaxDeletions        int    `kong:"help='Fail if more rrsets than this limit would be deleted (0 means no limit)'"`
+	KeepUnmanaged       bool   `kong:"help='Do not delete the rrsets which are not in the zone file'"`
+	AllowApexNSDeletion bool   `kong:"name='allow-apex-ns-deletion',help='Allow the deletion of the apex NS and SOA records'"`
+}
+
+func (d *liveDNSSyncRecordsCmd) Run(g *globals) error {
+	fqdn := c.LiveDNS.Manage.Name.Name
+	l := g.liveDNSHandle
+	text, err := readInput(d.File)
+	if err != nil {
+		return err
+	}
+	desired, err := zonefile.Parse(bytes.NewReader(text), fqdn)
+	if err != nil {
+		return err
+	}
+	plan, err := l.PlanZone(fqdn, desired, livedns.PlanOptions{
+		MaxDeletions:        d.MaxDeletions,
+		KeepUnmanaged:       d.KeepUnmanaged,
+		AllowApexNSDeletion: d.AllowApexNSDeletion,
+	})
+	if err != nil {
+		return err
+	}
+	fmt.Print(plan)
+	if c.DryRun || plan.Empty() {
+		return nil
+	}
+	return noPrint(l.ApplyPlan(plan))
+}
@@ -0,0 +1,90 @@
+package email
+
+import (
+	"strings"
+)
+
+// PatchMailbox updates the fields of the patch which are set
+func (e *Email) PatchMailbox(domain, mailbox_id string, patch MailboxPatch) (err error) {
+	_, err = e.client.Patch("mailboxes/"+domain+"/"+mailbox_id, patch, nil)
+	return
+}
+
+// SetAliases replaces the aliases of a mailbox, removing all of them
+// when aliases is empty
+func (e *Email) SetAliases(domain, mailbox_id string, aliases []string) error {
+	if aliases == nil {
+		aliases = []string{}
+	}
+	return e.PatchMailbox(domain, mailbox_id, MailboxPatch{Aliases: &aliases})
+}
+
+// AddAlias adds aliases to a mailbox, keeping its other aliases.
+// Aliases already present are ignored. The returned aliases are those
+// written.
+//
+// The aliases are read, modified and written back once. The mailbox
+// is updated asynchronously, so the new aliases may not be returned by
+// GetMailbox right away, and a concurrent modification of the aliases
+// between the read and the write is not detected.
+func (e *Email) AddAlias(domain, mailbox_id string, aliases ...string) ([]string, error) {
+	return e.modifyAliases(domain, mailbox_id, func(current []string) []string {
+		added := append([]string{}, current...)
+		for _, alias := range aliases {
+			if !containsAlias(added, alias) {
+				added = append(added, alias)
+			}
+		}
+		return added
+	})
+}
+
+// RemoveAlias removes aliases from a mailbox, keeping its other
+// aliases, as AddAlias does.
+func (e *Email) RemoveAlias(domain, mailbox_id string, aliases ...string) ([]string, error) {
+	return e.modifyAliases(domain, mailbox_id, func(current []string) []string {
+		kept := []string{}
+		for _, alias := range current {
+			if !containsAlias(aliases, alias) {
+				kept = append(kept, alias)
+			}
+		}
+		return kept
+	})
+}
+
+func (e *Email) modifyAliases(domain, mailbox_id string, modify func([]string) []string) ([]string, error) {
+	mailbox, err := e.GetMailbox(domain, mailbox_id)
+	if err != nil {
+		return nil, err
+	}
+	desired := modify(mailbox.Aliases)
+	if sameAliases(mailbox.Aliases, desired) {
+		return desired, nil
+	}
+	if err := e.SetAliases(domain, mailbox_id, desired); err != nil {
+		return nil, err
+	}
+	return desired, nil
+}
+
+func sameAliases(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for _, alias := range b {
+		if !containsAlias(a, alias) {
+			return false
+		}
+	}
+	return true
+}
+
+func containsAlias(aliases []string, alias string) bool {
+	for _, a := range aliases {
+		if strings.EqualFold(a, alias) {
+			return true
+		}
+	}
+	return false
+}
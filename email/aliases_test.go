@@ -0,0 +1,59 @@
+package email_test
+
+import (
+	"testing"
+
+	"github.com/go-gandi/go-gandi/config"
+	"github.com/go-gandi/go-gandi/email"
+	"gopkg.in/h2non/gock.v1"
+)
+
+func TestAddAlias(t *testing.T) {
+	defer gock.Off()
+	gock.New("https://api.gandi.net/v5/").
+		Get("email/mailboxes/example.com/mbox-id").
+		Reply(200).
+		JSON(email.MailboxResponse{Login: "alice", Aliases: []string{"contact"}})
+	gock.New("https://api.gandi.net/v5/").
+		Patch("email/mailboxes/example.com/mbox-id").
+		JSON(map[string][]string{"aliases": {"contact", "sales"}}).
+		Reply(202).
+		JSON(map[string]string{"message": "The mailbox is being updated"})
+
+	aliases, err := email.New(config.Config{}).AddAlias("example.com", "mbox-id", "sales", "Contact")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(aliases) != 2 {
+		t.Fatalf("Unexpected aliases %v", aliases)
+	}
+	if !gock.IsDone() {
+		t.Fatal("All the expected requests have not been sent")
+	}
+}
+
+func TestPatchMailboxKeepsAliases(t *testing.T) {
+	defer gock.Off()
+	gock.New("https://api.gandi.net/v5/").
+		Patch("email/mailboxes/example.com/mbox-id").
+		JSON(map[string]string{"password": "secret"}).
+		Reply(202).
+		JSON(map[string]string{"message": "The mailbox is being updated"})
+	gock.New("https://api.gandi.net/v5/").
+		Patch("email/mailboxes/example.com/mbox-id").
+		JSON(map[string][]string{"aliases": {}}).
+		Reply(202).
+		JSON(map[string]string{"message": "The mailbox is being updated"})
+
+	client := email.New(config.Config{})
+	password := "secret"
+	if err := client.PatchMailbox("example.com", "mbox-id", email.MailboxPatch{Password: &password}); err != nil {
+		t.Fatal(err)
+	}
+	if err := client.SetAliases("example.com", "mbox-id", nil); err != nil {
+		t.Fatal(err)
+	}
+	if !gock.IsDone() {
+		t.Fatal("All the expected requests have not been sent")
+	}
+}
@@ -0,0 +1,309 @@
+package email
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"sync"
+
+	"gopkg.in/yaml.v2"
+)
+
+// MailboxSpec describes a mailbox to provision
+type MailboxSpec struct {
+	Login string `yaml:"login" json:"login"`
+	// MailboxType is MailboxStandard if empty
+	MailboxType string `yaml:"type" json:"type,omitempty"`
+	// Password is generated if empty
+	Password string   `yaml:"password" json:"password,omitempty"`
+	Aliases  []string `yaml:"aliases" json:"aliases,omitempty"`
+	// Forwards are sources of the domain forwarded to the mailbox,
+	// for shared addresses such as info or sales. Sources listed by
+	// several mailboxes are forwarded to all of them.
+	Forwards []string `yaml:"forwards" json:"forwards,omitempty"`
+}
+
+// ProvisionResult is the outcome of the provisioning of a mailbox
+type ProvisionResult struct {
+	// Row is the position of the mailbox in the input, from 1
+	Row     int    `json:"row"`
+	Login   string `json:"login"`
+	Address string `json:"address"`
+	// Password is only reported when it has been generated, so that
+	// it can be sent to the user of the mailbox
+	Password string `json:"password,omitempty"`
+	Created  bool   `json:"created"`
+	Error    string `json:"error,omitempty"`
+}
+
+// ProvisionOptions tunes the provisioning of mailboxes
+type ProvisionOptions struct {
+	// Concurrency is the maximum number of mailboxes created at the
+	// same time, 4 if zero
+	Concurrency int
+	// PasswordLength of the generated passwords,
+	// DefaultPasswordLength if zero
+	PasswordLength int
+	// CheckSlots checks the free slots before the creations, which
+	// needs the permission to read the slots
+	CheckSlots bool
+}
+
+// ParseMailboxesCSV reads mailboxes from CSV with a header line
+// naming the columns among login, type, password, aliases and
+// forwards. Aliases and forwards are separated by spaces or
+// semicolons.
+func ParseMailboxesCSV(r io.Reader) ([]MailboxSpec, error) {
+	reader := csv.NewReader(r)
+	reader.TrimLeadingSpace = true
+	reader.FieldsPerRecord = -1
+	reader.Comment = '#'
+	header, err := reader.Read()
+	if err != nil {
+		return nil, fmt.Errorf("Fail to read the CSV header (error '%w')", err)
+	}
+	columns := map[string]int{}
+	for i, name := range header {
+		name = strings.ToLower(strings.TrimSpace(name))
+		switch name {
+		case "login", "type", "password", "aliases", "forwards":
+			columns[name] = i
+		default:
+			return nil, fmt.Errorf("Unknown CSV column '%s'", name)
+		}
+	}
+	if _, ok := columns["login"]; !ok {
+		return nil, fmt.Errorf("The CSV has no login column")
+	}
+	var specs []MailboxSpec
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			return specs, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		field := func(name string) string {
+			if i, ok := columns[name]; ok && i < len(record) {
+				return strings.TrimSpace(record[i])
+			}
+			return ""
+		}
+		specs = append(specs, MailboxSpec{
+			Login:       field("login"),
+			MailboxType: field("type"),
+			Password:    field("password"),
+			Aliases:     splitList(field("aliases")),
+			Forwards:    splitList(field("forwards")),
+		})
+	}
+}
+
+// ParseMailboxesYAML reads mailboxes from a YAML list of objects with
+// the login, type, password, aliases and forwards keys
+func ParseMailboxesYAML(r io.Reader) ([]MailboxSpec, error) {
+	var specs []MailboxSpec
+	decoder := yaml.NewDecoder(r)
+	decoder.SetStrict(true)
+	if err := decoder.Decode(&specs); err != nil && err != io.EOF {
+		return nil, fmt.Errorf("Fail to read the YAML mailboxes (error '%w')", err)
+	}
+	return specs, nil
+}
+
+func splitList(s string) []string {
+	return strings.FieldsFunc(s, func(r rune) bool {
+		return r == ';' || r == ' ' || r == '\t'
+	})
+}
+
+// ProvisionMailboxes creates the mailboxes of a domain, then the
+// forwardings to them. Missing passwords are generated and returned
+// in the results, which are in the order of the specs. A failure
+// does not stop the other creations: it is reported in the result of
+// the mailbox.
+//
+// When opts.CheckSlots is set, the free slots are checked once before
+// the creations, and the mailboxes exceeding them fail with
+// ErrNoFreeSlot. Otherwise the API rejects the mailboxes without slot.
+func (e *Email) ProvisionMailboxes(domain string, specs []MailboxSpec, opts ProvisionOptions) ([]ProvisionResult, error) {
+	var free map[string]int
+	if opts.CheckSlots {
+		usage, err := e.GetSlotUsage(domain)
+		if err != nil {
+			return nil, fmt.Errorf("Fail to check the mailbox slots of '%s' (error '%w')", domain, err)
+		}
+		free = map[string]int{}
+		for _, u := range usage {
+			free[u.MailboxType] = u.Free
+		}
+	}
+
+	results := make([]ProvisionResult, len(specs))
+	requests := make([]*CreateEmailRequest, len(specs))
+	seen := map[string]bool{}
+	for i, spec := range specs {
+		login := strings.ToLower(spec.Login)
+		results[i] = ProvisionResult{Row: i + 1, Login: login, Address: login + "@" + domain}
+		req, generated, err := prepareMailbox(spec, opts)
+		switch {
+		case err != nil:
+		case seen[login]:
+			err = fmt.Errorf("The login '%s' is provisioned twice", login)
+		case free != nil && free[req.MailboxType] == 0:
+			err = fmt.Errorf("%w: the domain '%s' has no %s slot available", ErrNoFreeSlot, domain, req.MailboxType)
+		}
+		seen[login] = true
+		if err != nil {
+			results[i].Error = err.Error()
+			continue
+		}
+		if free != nil {
+			free[req.MailboxType]--
+		}
+		if generated {
+			results[i].Password = req.Password
+		}
+		requests[i] = &req
+	}
+
+	concurrency := opts.Concurrency
+	if concurrency <= 0 {
+		concurrency = 4
+	}
+	var wg sync.WaitGroup
+	limit := make(chan struct{}, concurrency)
+	for i, req := range requests {
+		if req == nil {
+			continue
+		}
+		wg.Add(1)
+		limit <- struct{}{}
+		go func(i int, req CreateEmailRequest) {
+			defer func() { <-limit; wg.Done() }()
+			if err := e.CreateEmail(domain, req); err != nil {
+				results[i].Error = err.Error()
+				results[i].Password = ""
+				return
+			}
+			results[i].Created = true
+		}(i, *req)
+	}
+	wg.Wait()
+
+	e.provisionForwards(domain, specs, results)
+	return results, nil
+}
+
+// prepareMailbox validates a spec and returns its creation request,
+// and whether its password has been generated
+func prepareMailbox(spec MailboxSpec, opts ProvisionOptions) (CreateEmailRequest, bool, error) {
+	req := CreateEmailRequest{
+		Login:       strings.ToLower(spec.Login),
+		MailboxType: strings.ToLower(spec.MailboxType),
+		Password:    spec.Password,
+		Aliases:     spec.Aliases,
+	}
+	if req.Login == "" || strings.ContainsAny(req.Login, "@ \t") {
+		return req, false, fmt.Errorf("Invalid login '%s'", spec.Login)
+	}
+	switch req.MailboxType {
+	case "":
+		req.MailboxType = MailboxStandard
+	case MailboxStandard, MailboxPremium:
+	default:
+		return req, false, fmt.Errorf("Unknown mailbox type '%s'", spec.MailboxType)
+	}
+	if req.Password != "" {
+		return req, false, ValidatePassword(req.Password)
+	}
+	password, err := GeneratePassword(opts.PasswordLength)
+	req.Password = password
+	return req, true, err
+}
+
+// provisionForwards creates the forwardings of the created mailboxes,
+// adding them to the destinations of existing forwardings. Errors are
+// reported on the results of the mailboxes of the forwarding.
+func (e *Email) provisionForwards(domain string, specs []MailboxSpec, results []ProvisionResult) {
+	destinations := map[string][]string{}
+	rows := map[string][]int{}
+	for i, spec := range specs {
+		if !results[i].Created {
+			continue
+		}
+		for _, source := range spec.Forwards {
+			source, err := normalizeSource(domain, source)
+			if err != nil {
+				results[i].Error = err.Error()
+				continue
+			}
+			destinations[source] = append(destinations[source], results[i].Address)
+			rows[source] = append(rows[source], i)
+		}
+	}
+	if len(destinations) == 0 {
+		return
+	}
+	fail := func(source string, err error) {
+		for _, i := range rows[source] {
+			results[i].Error = fmt.Sprintf("Fail to forward '%s' (error '%s')", source, err)
+		}
+	}
+	current, err := e.GetForwards(domain)
+	if err != nil {
+		for source := range destinations {
+			fail(source, err)
+		}
+		return
+	}
+	existing := map[string][]string{}
+	for _, forward := range current {
+		existing[strings.ToLower(forward.Source)] = forward.Destinations
+	}
+	sources := make([]string, 0, len(destinations))
+	for source := range destinations {
+		sources = append(sources, source)
+	}
+	sort.Strings(sources)
+	for _, source := range sources {
+		var err error
+		if current, ok := existing[source]; ok {
+			merged := uniqueSorted(append(lower(current), destinations[source]...))
+			err = e.UpdateForward(domain, source, UpdateForwardRequest{Destinations: merged})
+		} else {
+			err = e.CreateForward(domain, CreateForwardRequest{Source: source, Destinations: uniqueSorted(destinations[source])})
+		}
+		if err != nil {
+			fail(source, err)
+		}
+	}
+}
+
+// WriteProvisionReport writes the results as CSV, with the row,
+// login, address, status, generated password and error columns. The
+// report contains the generated passwords and must be kept private.
+func WriteProvisionReport(w io.Writer, results []ProvisionResult) error {
+	writer := csv.NewWriter(w)
+	if err := writer.Write([]string{"row", "login", "address", "status", "password", "error"}); err != nil {
+		return err
+	}
+	for _, r := range results {
+		status := "failed"
+		switch {
+		case r.Created && r.Error != "":
+			status = "partial"
+		case r.Created:
+			status = "created"
+		}
+		record := []string{fmt.Sprint(r.Row), r.Login, r.Address, status, r.Password, r.Error}
+		if err := writer.Write(record); err != nil {
+			return err
+		}
+	}
+	writer.Flush()
+	return writer.Error()
+}
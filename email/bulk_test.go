@@ -0,0 +1,149 @@
+package email_test
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/go-gandi/go-gandi/config"
+	"github.com/go-gandi/go-gandi/email"
+	"gopkg.in/h2non/gock.v1"
+)
+
+func TestParseMailboxes(t *testing.T) {
+	csv := `login,type,aliases,forwards
+# A comment
+alice,premium,"contact;a.smith",info sales
+bob,,,
+`
+	yaml := `
+- login: alice
+  type: premium
+  aliases: [contact, a.smith]
+  forwards: [info, sales]
+- login: bob
+`
+	fromCSV, err := email.ParseMailboxesCSV(strings.NewReader(csv))
+	if err != nil {
+		t.Fatal(err)
+	}
+	fromYAML, err := email.ParseMailboxesYAML(strings.NewReader(yaml))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, specs := range [][]email.MailboxSpec{fromCSV, fromYAML} {
+		if len(specs) != 2 || specs[0].Login != "alice" || specs[0].MailboxType != "premium" ||
+			len(specs[0].Aliases) != 2 || len(specs[0].Forwards) != 2 || specs[1].Login != "bob" {
+			t.Errorf("Unexpected mailboxes %+v", specs)
+		}
+	}
+	if _, err := email.ParseMailboxesCSV(strings.NewReader("login,quota\n")); err == nil {
+		t.Error("An unknown column should be rejected")
+	}
+}
+
+func TestGeneratePassword(t *testing.T) {
+	for i := 0; i < 100; i++ {
+		password, err := email.GeneratePassword(0)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(password) != email.DefaultPasswordLength {
+			t.Fatalf("Unexpected length of '%s'", password)
+		}
+		if err := email.ValidatePassword(password); err != nil {
+			t.Fatalf("The password '%s' does not meet the policy: %s", password, err)
+		}
+	}
+	if err := email.ValidatePassword("password12"); err == nil {
+		t.Error("A password without uppercase letter should be rejected")
+	}
+}
+
+func TestProvisionMailboxes(t *testing.T) {
+	defer gock.Off()
+	gock.New("https://api.gandi.net/v5/").
+		Get("email/slots/example.com").
+		Reply(200).
+		JSON([]email.Slot{{ID: 1, MailboxType: email.MailboxStandard}, {ID: 2, MailboxType: email.MailboxStandard}})
+	gock.New("https://api.gandi.net/v5/").
+		Get("email/mailboxes/example.com").
+		Reply(200).
+		JSON([]email.ListMailboxResponse{})
+	gock.New("https://api.gandi.net/v5/").
+		Post("email/mailboxes/example.com").
+		Times(2).
+		Reply(202).
+		JSON(map[string]string{"message": "The email address is being created"})
+	gock.New("https://api.gandi.net/v5/").
+		Get("email/forwards/example.com").
+		Reply(200).
+		JSON([]email.ForwardResponse{{Source: "info", Destinations: []string{"boss@example.org"}}})
+	gock.New("https://api.gandi.net/v5/").
+		Put("email/forwards/example.com/info").
+		JSON(email.UpdateForwardRequest{Destinations: []string{"alice@example.com", "bob@example.com", "boss@example.org"}}).
+		Reply(200).
+		JSON(map[string]string{"message": "The forwarding has been updated"})
+
+	specs := []email.MailboxSpec{
+		{Login: "alice", Forwards: []string{"info"}},
+		{Login: "bob", Password: "Secret1234", Forwards: []string{"info"}},
+		{Login: "carol"},
+		{Login: "dave", Password: "weak"},
+	}
+	results, err := email.New(config.Config{}).ProvisionMailboxes("example.com", specs, email.ProvisionOptions{Concurrency: 2, CheckSlots: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !results[0].Created || results[0].Password == "" || results[0].Error != "" {
+		t.Errorf("alice should be created with a generated password: %+v", results[0])
+	}
+	if !results[1].Created || results[1].Password != "" {
+		t.Errorf("bob should be created without reporting his password: %+v", results[1])
+	}
+	if results[2].Created || !strings.Contains(results[2].Error, email.ErrNoFreeSlot.Error()) {
+		t.Errorf("carol should fail for lack of slot: %+v", results[2])
+	}
+	if results[3].Created || results[3].Error == "" {
+		t.Errorf("dave should fail for his weak password: %+v", results[3])
+	}
+	if !gock.IsDone() {
+		t.Fatal("All the expected requests have not been sent")
+	}
+
+	var report bytes.Buffer
+	if err := email.WriteProvisionReport(&report, results); err != nil {
+		t.Fatal(err)
+	}
+	if lines := strings.Count(report.String(), "\n"); lines != 5 {
+		t.Errorf("Expected a header and 4 rows in the report, got\n%s", report.String())
+	}
+}
+
+func TestProvisionMailboxesWithoutSlotCheck(t *testing.T) {
+	defer gock.Off()
+	// The slots are not read, the API rejects the mailboxes without
+	// slot
+	gock.New("https://api.gandi.net/v5/").
+		Post("email/mailboxes/example.com").
+		Times(2).
+		Reply(202).
+		JSON(map[string]string{"message": "The email address is being created"})
+
+	specs := []email.MailboxSpec{
+		{Login: "alice", Password: "Secret1234"},
+		{Login: "bob", Password: "Secret1234"},
+	}
+	results, err := email.New(config.Config{}).ProvisionMailboxes("example.com", specs, email.ProvisionOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, result := range results {
+		if !result.Created {
+			t.Errorf("The mailbox should be created: %+v", result)
+		}
+	}
+	if !gock.IsDone() {
+		t.Fatal("All the expected requests have not been sent")
+	}
+}
@@ -2,9 +2,14 @@ package email
 
 import (
 	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
 
 	"github.com/go-gandi/go-gandi/config"
 	"github.com/go-gandi/go-gandi/internal/client"
+	"github.com/go-gandi/go-gandi/types"
 )
 
 // New returns an instance of the Email API client
@@ -22,7 +27,7 @@ func NewFromClient(g client.Gandi) *Email {
 
 // ListMailboxes list mailboxes attached to domain
 func (e *Email) ListMailboxes(domain string) (mailboxes []ListMailboxResponse, err error) {
-	_, elements, err := e.client.GetCollection("/mailboxes/"+domain, nil)
+	_, elements, err := e.client.GetCollection("mailboxes/"+domain, nil)
 	if err != nil {
 		return nil, err
 	}
@@ -43,12 +48,44 @@ func (e *Email) GetMailbox(domain, mailbox_id string) (mailbox MailboxResponse,
 	return
 }
 
+// ErrNoFreeSlot is returned by CreateEmailChecked and
+// ProvisionMailboxes when the domain has no free slot of the mailbox
+// type, see CreateSlot
+var ErrNoFreeSlot = errors.New("No free mailbox slot")
+
 // CreateEmail creates a new mailbox for the given domain
 func (e *Email) CreateEmail(domain string, req CreateEmailRequest) (err error) {
 	_, err = e.client.Post("mailboxes/"+domain, req, nil)
 	return
 }
 
+// CreateEmailChecked creates a new mailbox like CreateEmail, but first
+// checks the domain has a free slot for the mailbox type, failing
+// with ErrNoFreeSlot instead of an API error otherwise. It costs the
+// listing of the slots and of the mailboxes, which requires the
+// permission to read the slots, and does not reserve the slot, so
+// concurrent creations can still run out of slots.
+func (e *Email) CreateEmailChecked(domain string, req CreateEmailRequest) (err error) {
+	mailboxType := req.MailboxType
+	if mailboxType == "" {
+		mailboxType = MailboxStandard
+	}
+	usage, err := e.GetSlotUsage(domain)
+	if err != nil {
+		return fmt.Errorf("Fail to check the mailbox slots of '%s' (error '%w')", domain, err)
+	}
+	free := 0
+	for _, u := range usage {
+		if u.MailboxType == mailboxType {
+			free = u.Free
+		}
+	}
+	if free == 0 {
+		return fmt.Errorf("%w: the domain '%s' has no %s slot available", ErrNoFreeSlot, domain, mailboxType)
+	}
+	return e.CreateEmail(domain, req)
+}
+
 // UpdateEmail update mailbox parameters
 func (e *Email) UpdateEmail(domain, mailbox_id string, req UpdateEmailRequest) (err error) {
 	_, err = e.client.Patch("mailboxes/"+domain+"/"+mailbox_id, req, nil)
@@ -61,6 +98,112 @@ func (e *Email) DeleteEmail(domain, mailbox_id string) (err error) {
 	return
 }
 
+// SetResponder sets the automatic reply of a mailbox. A disabled
+// responder stops the replies.
+func (e *Email) SetResponder(domain, mailbox_id string, responder Responder) (err error) {
+	if responder.Enabled && responder.Message == "" {
+		return fmt.Errorf("An enabled responder requires a message")
+	}
+	if responder.StartsAt != nil && responder.EndsAt != nil && !responder.EndsAt.After(*responder.StartsAt) {
+		return fmt.Errorf("The responder must end after it starts")
+	}
+	_, err = e.client.Patch("mailboxes/"+domain+"/"+mailbox_id, responderRequest{Responder: responder}, nil)
+	return
+}
+
+// SetAntispam enables or disables the antispam of a mailbox
+func (e *Email) SetAntispam(domain, mailbox_id string, enabled bool) (err error) {
+	_, err = e.client.Patch("mailboxes/"+domain+"/"+mailbox_id, antispamRequest{Antispam: enabled}, nil)
+	return
+}
+
+// PurgeMailbox deletes all the messages of a mailbox, which is kept
+func (e *Email) PurgeMailbox(domain, mailbox_id string) (err error) {
+	_, err = e.client.Delete("mailboxes/"+domain+"/"+mailbox_id+"/contents", nil, nil)
+	return
+}
+
+// RenewMailbox renews a mailbox for a duration in months
+func (e *Email) RenewMailbox(domain, mailbox_id string, duration int) (err error) {
+	if duration < 1 {
+		return fmt.Errorf("The renewal duration must be at least one month")
+	}
+	_, err = e.client.Post("mailboxes/"+domain+"/"+mailbox_id+"/renew", renewRequest{Duration: duration}, nil)
+	return
+}
+
+// UpgradeMailbox changes the type of a mailbox, for instance from
+// MailboxStandard to MailboxPremium. The domain needs a free slot of
+// the new type.
+func (e *Email) UpgradeMailbox(domain, mailbox_id, mailboxType string) (err error) {
+	_, err = e.client.Patch("mailboxes/"+domain+"/"+mailbox_id+"/type", upgradeRequest{MailboxType: mailboxType}, nil)
+	return
+}
+
+// ListSlots returns the mailbox slots of a domain
+func (e *Email) ListSlots(domain string) (slots []Slot, err error) {
+	_, err = e.client.Get("slots/"+domain, nil, &slots)
+	return
+}
+
+// GetSlot returns a mailbox slot of a domain
+func (e *Email) GetSlot(domain string, slot_id int) (slot Slot, err error) {
+	_, err = e.client.Get("slots/"+domain+"/"+strconv.Itoa(slot_id), nil, &slot)
+	return
+}
+
+// CreateSlot buys a mailbox slot of the mailbox type for a domain
+func (e *Email) CreateSlot(domain, mailboxType string) (response types.StandardResponse, err error) {
+	_, err = e.client.Post("slots/"+domain, createSlotRequest{MailboxType: mailboxType}, &response)
+	return
+}
+
+// DeleteSlot deletes a free slot of a domain, which is refunded if
+// it is refundable
+func (e *Email) DeleteSlot(domain string, slot_id int) (err error) {
+	_, err = e.client.Delete("slots/"+domain+"/"+strconv.Itoa(slot_id), nil, nil)
+	return
+}
+
+// GetSlotUsage returns the number of used and free slots of the
+// domain for each mailbox type
+func (e *Email) GetSlotUsage(domain string) (usage []SlotUsage, err error) {
+	slots, err := e.ListSlots(domain)
+	if err != nil {
+		return nil, err
+	}
+	mailboxes, err := e.ListMailboxes(domain)
+	if err != nil {
+		return nil, err
+	}
+	byType := map[string]*SlotUsage{}
+	var mailboxTypes []string
+	get := func(mailboxType string) *SlotUsage {
+		u, ok := byType[mailboxType]
+		if !ok {
+			u = &SlotUsage{MailboxType: mailboxType}
+			byType[mailboxType] = u
+			mailboxTypes = append(mailboxTypes, mailboxType)
+		}
+		return u
+	}
+	for _, slot := range slots {
+		get(slot.MailboxType).Total++
+	}
+	for _, mailbox := range mailboxes {
+		get(mailbox.MailboxType).Used++
+	}
+	sort.Strings(mailboxTypes)
+	for _, mailboxType := range mailboxTypes {
+		u := byType[mailboxType]
+		if u.Free = u.Total - u.Used; u.Free < 0 {
+			u.Free = 0
+		}
+		usage = append(usage, *u)
+	}
+	return usage, nil
+}
+
 // CreateForward creates forwarding
 func (e *Email) CreateForward(domain string, req CreateForwardRequest) (err error) {
 	_, err = e.client.Post("forwards/"+domain, req, nil)
@@ -68,7 +211,7 @@ func (e *Email) CreateForward(domain string, req CreateForwardRequest) (err erro
 }
 
 // GetForwards retrieves all forwardings for domain
-func (e *Email) GetForwards(domain string) (forwards []GetForwardRequest, err error) {
+func (e *Email) GetForwards(domain string) (forwards []ForwardResponse, err error) {
 	_, err = e.client.Get("forwards/"+domain, nil, &forwards)
 	return
 }
@@ -0,0 +1,212 @@
+package email_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/go-gandi/go-gandi/config"
+	"github.com/go-gandi/go-gandi/email"
+	"gopkg.in/h2non/gock.v1"
+)
+
+func mockSlots() {
+	gock.New("https://api.gandi.net/v5/").
+		Get("email/slots/example.com").
+		Reply(200).
+		JSON([]email.Slot{
+			{ID: 1, MailboxType: email.MailboxStandard},
+			{ID: 2, MailboxType: email.MailboxStandard},
+			{ID: 3, MailboxType: email.MailboxPremium},
+		})
+	gock.New("https://api.gandi.net/v5/").
+		Get("email/mailboxes/example.com").
+		Reply(200).
+		JSON([]email.ListMailboxResponse{
+			{Login: "alice", MailboxType: email.MailboxStandard},
+			{Login: "bob", MailboxType: email.MailboxPremium},
+		})
+}
+
+func TestGetSlotUsage(t *testing.T) {
+	defer gock.Off()
+	mockSlots()
+
+	usage, err := email.New(config.Config{}).GetSlotUsage("example.com")
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := []email.SlotUsage{
+		{MailboxType: email.MailboxPremium, Total: 1, Used: 1, Free: 0},
+		{MailboxType: email.MailboxStandard, Total: 2, Used: 1, Free: 1},
+	}
+	if len(usage) != len(expected) || usage[0] != expected[0] || usage[1] != expected[1] {
+		t.Fatalf("Expected %+v, got %+v", expected, usage)
+	}
+}
+
+func TestCreateEmailWithoutFreeSlot(t *testing.T) {
+	defer gock.Off()
+	mockSlots()
+
+	err := email.New(config.Config{}).CreateEmailChecked("example.com", email.CreateEmailRequest{
+		Login:       "carol",
+		MailboxType: email.MailboxPremium,
+		Password:    "secret",
+	})
+	if !errors.Is(err, email.ErrNoFreeSlot) {
+		t.Fatalf("Expected ErrNoFreeSlot, got %v", err)
+	}
+	if !gock.IsDone() {
+		t.Fatal("All the expected requests have not been sent")
+	}
+}
+
+func TestCreateEmailChecked(t *testing.T) {
+	defer gock.Off()
+	mockSlots()
+	gock.New("https://api.gandi.net/v5/").
+		Post("email/mailboxes/example.com").
+		JSON(email.CreateEmailRequest{Login: "carol", MailboxType: email.MailboxStandard, Password: "secret"}).
+		Reply(202).
+		JSON(map[string]string{"message": "The email address is being created"})
+
+	err := email.New(config.Config{}).CreateEmailChecked("example.com", email.CreateEmailRequest{
+		Login:       "carol",
+		MailboxType: email.MailboxStandard,
+		Password:    "secret",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !gock.IsDone() {
+		t.Fatal("All the expected requests have not been sent")
+	}
+}
+
+func TestCreateEmail(t *testing.T) {
+	defer gock.Off()
+	// A single request is sent, without checking the slots
+	gock.New("https://api.gandi.net/v5/").
+		Post("email/mailboxes/example.com").
+		JSON(email.CreateEmailRequest{Login: "carol", MailboxType: email.MailboxStandard, Password: "secret"}).
+		Reply(202).
+		JSON(map[string]string{"message": "The email address is being created"})
+
+	err := email.New(config.Config{}).CreateEmail("example.com", email.CreateEmailRequest{
+		Login:       "carol",
+		MailboxType: email.MailboxStandard,
+		Password:    "secret",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !gock.IsDone() {
+		t.Fatal("All the expected requests have not been sent")
+	}
+}
+
+func TestSetResponder(t *testing.T) {
+	defer gock.Off()
+	gock.New("https://api.gandi.net/v5/").
+		Patch("email/mailboxes/example.com/mailbox-id").
+		JSON(map[string]interface{}{"responder": map[string]interface{}{"enabled": true, "message": "Out of office"}}).
+		Reply(202).
+		JSON(map[string]string{"message": "The mailbox is being updated"})
+
+	err := email.New(config.Config{}).SetResponder("example.com", "mailbox-id", email.Responder{Enabled: true, Message: "Out of office"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !gock.IsDone() {
+		t.Fatal("All the expected requests have not been sent")
+	}
+}
+
+func TestSetResponderWithoutMessage(t *testing.T) {
+	defer gock.Off()
+	// No request is sent
+	err := email.New(config.Config{}).SetResponder("example.com", "mailbox-id", email.Responder{Enabled: true})
+	if err == nil {
+		t.Fatal("An enabled responder without message should be rejected")
+	}
+}
+
+func TestSetAntispam(t *testing.T) {
+	defer gock.Off()
+	gock.New("https://api.gandi.net/v5/").
+		Patch("email/mailboxes/example.com/mailbox-id").
+		JSON(map[string]bool{"antispam": false}).
+		Reply(202).
+		JSON(map[string]string{"message": "The mailbox is being updated"})
+
+	if err := email.New(config.Config{}).SetAntispam("example.com", "mailbox-id", false); err != nil {
+		t.Fatal(err)
+	}
+	if !gock.IsDone() {
+		t.Fatal("All the expected requests have not been sent")
+	}
+}
+
+func TestPurgeMailbox(t *testing.T) {
+	defer gock.Off()
+	gock.New("https://api.gandi.net/v5/").
+		Delete("email/mailboxes/example.com/mailbox-id/contents").
+		Reply(202).
+		JSON(map[string]string{"message": "The mailbox is being purged"})
+
+	if err := email.New(config.Config{}).PurgeMailbox("example.com", "mailbox-id"); err != nil {
+		t.Fatal(err)
+	}
+	if !gock.IsDone() {
+		t.Fatal("All the expected requests have not been sent")
+	}
+}
+
+func TestRenewMailbox(t *testing.T) {
+	defer gock.Off()
+	gock.New("https://api.gandi.net/v5/").
+		Post("email/mailboxes/example.com/mailbox-id/renew").
+		JSON(map[string]int{"duration": 12}).
+		Reply(202).
+		JSON(map[string]string{"message": "The mailbox is being renewed"})
+
+	if err := email.New(config.Config{}).RenewMailbox("example.com", "mailbox-id", 12); err != nil {
+		t.Fatal(err)
+	}
+	if !gock.IsDone() {
+		t.Fatal("All the expected requests have not been sent")
+	}
+	// No request is sent for an invalid duration
+	if err := email.New(config.Config{}).RenewMailbox("example.com", "mailbox-id", 0); err == nil {
+		t.Fatal("A renewal of zero month should be rejected")
+	}
+}
+
+func TestUpgradeMailbox(t *testing.T) {
+	defer gock.Off()
+	gock.New("https://api.gandi.net/v5/").
+		Patch("email/mailboxes/example.com/mailbox-id/type").
+		JSON(map[string]string{"mailbox_type": email.MailboxPremium}).
+		Reply(202).
+		JSON(map[string]string{"message": "The mailbox is being upgraded"})
+
+	if err := email.New(config.Config{}).UpgradeMailbox("example.com", "mailbox-id", email.MailboxPremium); err != nil {
+		t.Fatal(err)
+	}
+	if !gock.IsDone() {
+		t.Fatal("All the expected requests have not been sent")
+	}
+}
+
+func TestListSlots(t *testing.T) {
+	defer gock.Off()
+	mockSlots()
+
+	slots, err := email.New(config.Config{}).ListSlots("example.com")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(slots) != 3 || slots[2].ID != 3 || slots[2].MailboxType != email.MailboxPremium {
+		t.Fatalf("Unexpected slots %+v", slots)
+	}
+}
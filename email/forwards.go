@@ -0,0 +1,339 @@
+package email
+
+import (
+	"fmt"
+	"net/mail"
+	"sort"
+	"strings"
+)
+
+// CatchAll is the source of the forwarding receiving the messages
+// sent to the addresses of the domain which have neither a mailbox
+// nor a forwarding
+const CatchAll = "*"
+
+// ForwardChangeType is the kind of change applied to a forwarding
+type ForwardChangeType string
+
+const (
+	// ForwardCreate creates a forwarding
+	ForwardCreate ForwardChangeType = "create"
+	// ForwardUpdate replaces the destinations of a forwarding
+	ForwardUpdate ForwardChangeType = "update"
+	// ForwardDelete deletes a forwarding
+	ForwardDelete ForwardChangeType = "delete"
+)
+
+// ForwardChange is a change of the forwarding of a source
+type ForwardChange struct {
+	Type    ForwardChangeType `json:"type"`
+	Source  string            `json:"source"`
+	Current []string          `json:"current,omitempty"`
+	Desired []string          `json:"desired,omitempty"`
+}
+
+func (c ForwardChange) String() string {
+	switch c.Type {
+	case ForwardCreate:
+		return fmt.Sprintf("+ %s -> %s", c.Source, strings.Join(c.Desired, ", "))
+	case ForwardUpdate:
+		return fmt.Sprintf("~ %s -> %s (was %s)", c.Source, strings.Join(c.Desired, ", "), strings.Join(c.Current, ", "))
+	}
+	return fmt.Sprintf("- %s -> %s", c.Source, strings.Join(c.Current, ", "))
+}
+
+// ForwardPlan is the list of changes getting the forwardings of a
+// domain to a desired state
+type ForwardPlan struct {
+	Domain  string          `json:"domain"`
+	Changes []ForwardChange `json:"changes"`
+}
+
+// Empty returns whether the plan has no change
+func (p ForwardPlan) Empty() bool {
+	return len(p.Changes) == 0
+}
+
+func (p ForwardPlan) String() string {
+	var b strings.Builder
+	for _, c := range p.Changes {
+		b.WriteString(c.String())
+		b.WriteString("\n")
+	}
+	return b.String()
+}
+
+// normalizeSource returns the local part of a source, which may be
+// given as a full address of the domain
+func normalizeSource(domain, source string) (string, error) {
+	source = strings.ToLower(strings.TrimSpace(source))
+	if i := strings.LastIndex(source, "@"); i >= 0 {
+		if !strings.EqualFold(source[i+1:], domain) {
+			return "", fmt.Errorf("The source '%s' is not an address of '%s'", source, domain)
+		}
+		source = source[:i]
+	}
+	if source == "" || strings.ContainsAny(source, " \t,;<>") || (source != CatchAll && strings.Contains(source, "*")) {
+		return "", fmt.Errorf("Invalid forwarding source '%s'", source)
+	}
+	return source, nil
+}
+
+// validDestination checks that a destination is a bare address
+func validDestination(destination string) error {
+	address, err := mail.ParseAddress(destination)
+	if err != nil || address.Address != destination || address.Name != "" {
+		return fmt.Errorf("Invalid destination address '%s'", destination)
+	}
+	at := strings.LastIndex(destination, "@")
+	if !strings.Contains(destination[at+1:], ".") {
+		return fmt.Errorf("The destination '%s' has no fully qualified domain", destination)
+	}
+	return nil
+}
+
+// normalizeForwards validates the desired forwardings of a domain and
+// returns them with normalized sources and sorted, deduplicated
+// destinations
+func normalizeForwards(domain string, desired map[string][]string) (map[string][]string, error) {
+	var errs []string
+	forwards := map[string][]string{}
+	for source, destinations := range desired {
+		local, err := normalizeSource(domain, source)
+		if err != nil {
+			errs = append(errs, err.Error())
+			continue
+		}
+		if _, ok := forwards[local]; ok {
+			errs = append(errs, fmt.Sprintf("The source '%s' is defined twice", local))
+			continue
+		}
+		if len(destinations) == 0 {
+			errs = append(errs, fmt.Sprintf("The source '%s' has no destination", local))
+			continue
+		}
+		var normalized []string
+		for _, destination := range destinations {
+			destination = strings.TrimSpace(destination)
+			if err := validDestination(destination); err != nil {
+				errs = append(errs, err.Error())
+				continue
+			}
+			normalized = append(normalized, strings.ToLower(destination))
+		}
+		forwards[local] = uniqueSorted(normalized)
+	}
+	if len(errs) > 0 {
+		sort.Strings(errs)
+		return nil, fmt.Errorf("Invalid forwardings: %s", strings.Join(errs, "; "))
+	}
+	return forwards, nil
+}
+
+// ValidateForwards checks the desired forwardings of a domain: the
+// sources must be addresses of the domain or CatchAll, the
+// destinations valid addresses, and no message may loop between the
+// addresses of the domain. The mailboxes are the logins and the
+// aliases of the mailboxes of the domain, which end the forwarding
+// chains; the other addresses of the domain are handled by the
+// catch-all.
+func ValidateForwards(domain string, desired map[string][]string, mailboxes []string) error {
+	forwards, err := normalizeForwards(domain, desired)
+	if err != nil {
+		return err
+	}
+	return detectLoops(domain, forwards, mailboxes)
+}
+
+func detectLoops(domain string, forwards map[string][]string, mailboxes []string) error {
+	isMailbox := map[string]bool{}
+	suffix := "@" + strings.ToLower(domain)
+	for _, login := range mailboxes {
+		isMailbox[strings.TrimSuffix(strings.ToLower(login), suffix)] = true
+	}
+	// next returns the sources receiving the messages sent to a
+	// destination of the domain
+	next := func(destination string) (string, bool) {
+		if !strings.HasSuffix(destination, suffix) {
+			return "", false
+		}
+		local := strings.TrimSuffix(destination, suffix)
+		if isMailbox[local] {
+			return "", false
+		}
+		if _, ok := forwards[local]; ok {
+			return local, true
+		}
+		if _, ok := forwards[CatchAll]; ok {
+			return CatchAll, true
+		}
+		return "", false
+	}
+
+	const (
+		visiting = 1
+		done     = 2
+	)
+	state := map[string]int{}
+	var visit func(source string, path []string) error
+	visit = func(source string, path []string) error {
+		switch state[source] {
+		case visiting:
+			return fmt.Errorf("Forwarding loop: %s -> %s", strings.Join(path, " -> "), source)
+		case done:
+			return nil
+		}
+		state[source] = visiting
+		for _, destination := range forwards[source] {
+			if target, ok := next(destination); ok {
+				if err := visit(target, append(path, source)); err != nil {
+					return err
+				}
+			}
+		}
+		state[source] = done
+		return nil
+	}
+	sources := make([]string, 0, len(forwards))
+	for source := range forwards {
+		sources = append(sources, source)
+	}
+	sort.Strings(sources)
+	for _, source := range sources {
+		if err := visit(source, nil); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// PlanForwards returns the changes getting the current forwardings to
+// the desired ones, where desired maps the sources to their
+// destinations. The desired forwardings must be valid, see
+// ValidateForwards.
+func PlanForwards(domain string, current []ForwardResponse, desired map[string][]string) (ForwardPlan, error) {
+	forwards, err := normalizeForwards(domain, desired)
+	if err != nil {
+		return ForwardPlan{}, err
+	}
+	plan := ForwardPlan{Domain: domain}
+	existing := map[string]bool{}
+	for _, forward := range current {
+		source := strings.ToLower(forward.Source)
+		existing[source] = true
+		destinations := uniqueSorted(lower(forward.Destinations))
+		wanted, ok := forwards[source]
+		switch {
+		case !ok:
+			plan.Changes = append(plan.Changes, ForwardChange{Type: ForwardDelete, Source: source, Current: destinations})
+		case !equalStrings(destinations, wanted):
+			plan.Changes = append(plan.Changes, ForwardChange{Type: ForwardUpdate, Source: source, Current: destinations, Desired: wanted})
+		}
+	}
+	for source, destinations := range forwards {
+		if !existing[source] {
+			plan.Changes = append(plan.Changes, ForwardChange{Type: ForwardCreate, Source: source, Desired: destinations})
+		}
+	}
+	sort.Slice(plan.Changes, func(i, j int) bool {
+		return plan.Changes[i].Source < plan.Changes[j].Source
+	})
+	return plan, nil
+}
+
+// PlanForwards validates the desired forwardings of a domain and
+// returns the changes getting the current forwardings to them
+func (e *Email) PlanForwards(domain string, desired map[string][]string) (ForwardPlan, error) {
+	mailboxes, err := e.ListMailboxes(domain)
+	if err != nil {
+		return ForwardPlan{}, err
+	}
+	// The aliases are only returned with each mailbox
+	var addresses []string
+	for _, mailbox := range mailboxes {
+		details, err := e.GetMailbox(domain, mailbox.ID)
+		if err != nil {
+			return ForwardPlan{}, fmt.Errorf("Fail to get the aliases of the mailbox '%s' (error '%w')", mailbox.Login, err)
+		}
+		addresses = append(addresses, mailbox.Login)
+		addresses = append(addresses, details.Aliases...)
+	}
+	if err := ValidateForwards(domain, desired, addresses); err != nil {
+		return ForwardPlan{}, err
+	}
+	current, err := e.GetForwards(domain)
+	if err != nil {
+		return ForwardPlan{}, err
+	}
+	return PlanForwards(domain, current, desired)
+}
+
+// ApplyForwardPlan applies the changes of a plan. Deletions are
+// applied first. It stops at the first failure, leaving the previous
+// changes applied.
+func (e *Email) ApplyForwardPlan(plan ForwardPlan) error {
+	for _, changeType := range []ForwardChangeType{ForwardDelete, ForwardUpdate, ForwardCreate} {
+		for _, c := range plan.Changes {
+			if c.Type != changeType {
+				continue
+			}
+			var err error
+			switch c.Type {
+			case ForwardDelete:
+				err = e.DeleteForward(plan.Domain, c.Source)
+			case ForwardUpdate:
+				err = e.UpdateForward(plan.Domain, c.Source, UpdateForwardRequest{Destinations: c.Desired})
+			case ForwardCreate:
+				err = e.CreateForward(plan.Domain, CreateForwardRequest{Source: c.Source, Destinations: c.Desired})
+			}
+			if err != nil {
+				return fmt.Errorf("Fail to %s the forwarding of '%s' (error '%w')", c.Type, c.Source, err)
+			}
+		}
+	}
+	return nil
+}
+
+// ReconcileForwards makes the forwardings of a domain match the
+// desired ones, mapping the sources to their destinations, and
+// returns the applied plan. The forwardings of the domain which are
+// not desired are deleted.
+func (e *Email) ReconcileForwards(domain string, desired map[string][]string) (ForwardPlan, error) {
+	plan, err := e.PlanForwards(domain, desired)
+	if err != nil {
+		return plan, err
+	}
+	return plan, e.ApplyForwardPlan(plan)
+}
+
+func lower(values []string) []string {
+	lowered := make([]string, len(values))
+	for i, v := range values {
+		lowered[i] = strings.ToLower(v)
+	}
+	return lowered
+}
+
+func uniqueSorted(values []string) []string {
+	sorted := append([]string{}, values...)
+	sort.Strings(sorted)
+	unique := sorted[:0]
+	for i, v := range sorted {
+		if i == 0 || v != sorted[i-1] {
+			unique = append(unique, v)
+		}
+	}
+	return unique
+}
+
+func equalStrings(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
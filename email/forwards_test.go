@@ -0,0 +1,91 @@
+package email_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/go-gandi/go-gandi/config"
+	"github.com/go-gandi/go-gandi/email"
+	"gopkg.in/h2non/gock.v1"
+)
+
+func TestPlanForwards(t *testing.T) {
+	current := []email.ForwardResponse{
+		{Source: "info", Destinations: []string{"alice@example.org"}},
+		{Source: "sales", Destinations: []string{"bob@example.org", "alice@example.org"}},
+		{Source: "old", Destinations: []string{"carol@example.org"}},
+	}
+	desired := map[string][]string{
+		"info":              {"alice@example.org", "dave@example.org"},
+		"Sales@example.com": {"alice@example.org", "bob@example.org"},
+		"*":                 {"admin@example.org"},
+	}
+	plan, err := email.PlanForwards("example.com", current, desired)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := "+ * -> admin@example.org\n" +
+		"~ info -> alice@example.org, dave@example.org (was alice@example.org)\n" +
+		"- old -> carol@example.org\n"
+	if plan.String() != expected {
+		t.Fatalf("Expected the plan\n%s\ngot\n%s", expected, plan)
+	}
+}
+
+func TestValidateForwards(t *testing.T) {
+	cases := []struct {
+		name    string
+		desired map[string][]string
+		err     string
+	}{
+		{"valid", map[string][]string{"info": {"alice@example.com"}, "*": {"info@example.com"}}, ""},
+		{"catch-all loop", map[string][]string{"*": {"nobody@example.com"}}, "loop: * -> *"},
+		{"mailbox ends the chain", map[string][]string{"info": {"alice@example.com"}, "*": {"bob@example.com"}}, ""},
+		{"alias ends the chain", map[string][]string{"*": {"postmaster@example.com"}}, ""},
+		{"loop", map[string][]string{"a": {"b@example.com"}, "b": {"a@example.com"}}, "loop: a -> b -> a"},
+		{"self", map[string][]string{"a": {"a@example.com"}}, "loop: a -> a"},
+		{"invalid destination", map[string][]string{"a": {"Alice <alice@example.org>"}}, "Invalid destination"},
+		{"no domain", map[string][]string{"a": {"alice@localhost"}}, "no fully qualified domain"},
+		{"other domain", map[string][]string{"a@example.org": {"alice@example.net"}}, "not an address of"},
+		{"no destination", map[string][]string{"a": {}}, "no destination"},
+	}
+	for _, c := range cases {
+		// postmaster is an alias of a mailbox
+		err := email.ValidateForwards("example.com", c.desired, []string{"alice", "bob", "postmaster@example.com"})
+		switch {
+		case c.err == "" && err != nil:
+			t.Errorf("%s: unexpected error %s", c.name, err)
+		case c.err != "" && (err == nil || !strings.Contains(err.Error(), c.err)):
+			t.Errorf("%s: expected an error containing '%s', got %v", c.name, c.err, err)
+		}
+	}
+}
+
+func TestPlanForwardsToAlias(t *testing.T) {
+	defer gock.Off()
+	api := "https://api.gandi.net/v5/"
+	gock.New(api).
+		Get("email/mailboxes/example.com").
+		Reply(200).
+		JSON([]email.ListMailboxResponse{{ID: "alice-id", Login: "alice"}})
+	gock.New(api).
+		Get("email/mailboxes/example.com/alice-id").
+		Reply(200).
+		JSON(email.MailboxResponse{ID: "alice-id", Login: "alice", Aliases: []string{"contact"}})
+	gock.New(api).
+		Get("email/forwards/example.com").
+		Reply(200).
+		JSON([]email.ForwardResponse{})
+
+	// The alias is delivered to the mailbox, not to the catch-all
+	plan, err := email.New(config.Config{}).PlanForwards("example.com", map[string][]string{"*": {"contact@example.com"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if plan.String() != "+ * -> contact@example.com\n" {
+		t.Fatalf("Unexpected plan\n%s", plan)
+	}
+	if !gock.IsDone() {
+		t.Fatal("All the expected requests have not been sent")
+	}
+}
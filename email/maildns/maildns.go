@@ -0,0 +1,333 @@
+// Package maildns checks and creates in LiveDNS the DNS records
+// required by Gandi Mail: MX, SPF, the SRV and CNAME records used by
+// mail clients to discover the servers, DKIM and DMARC.
+//
+// Records conflicting with the expected ones, such as the MX of
+// another provider, are reported but only replaced when forced. The
+// SPF policy of the domain is merged instead: the Gandi include is
+// added to the existing policy.
+package maildns
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/go-gandi/go-gandi/livedns"
+	"github.com/go-gandi/go-gandi/livedns/rdata"
+)
+
+// SPFInclude is the SPF mechanism authorizing the Gandi Mail servers
+const SPFInclude = "include:_mailcust.gandi.net"
+
+// DefaultTTL is the TTL of the created records
+const DefaultTTL = 10800
+
+// Status is the state of a required record
+type Status string
+
+const (
+	// StatusOK means that the record is as expected
+	StatusOK Status = "ok"
+	// StatusMissing means that the record does not exist
+	StatusMissing Status = "missing"
+	// StatusConflict means that the record exists with other values
+	StatusConflict Status = "conflict"
+)
+
+// Options tunes the required records
+type Options struct {
+	// TTL of the created records, DefaultTTL if zero
+	TTL int
+	// DMARCPolicy is the policy of the created DMARC record, "none"
+	// if empty. An existing DMARC record is never changed.
+	DMARCPolicy string
+	// DMARCReport is the address receiving the aggregate DMARC
+	// reports, if any
+	DMARCReport string
+	// Force replaces the conflicting records
+	Force bool
+}
+
+// Check is the state of a required record
+type Check struct {
+	Name     string   `json:"name"`
+	Type     string   `json:"type"`
+	Expected []string `json:"expected"`
+	Current  []string `json:"current,omitempty"`
+	Status   Status   `json:"status"`
+	Message  string   `json:"message,omitempty"`
+	// Fixed is set by Apply when the record has been created or
+	// updated
+	Fixed bool `json:"fixed,omitempty"`
+}
+
+func (c Check) String() string {
+	s := fmt.Sprintf("%s %s %s: %s", c.Status, c.Name, c.Type, strings.Join(c.Expected, ", "))
+	if c.Message != "" {
+		s += " (" + c.Message + ")"
+	}
+	if c.Fixed {
+		s += " [fixed]"
+	}
+	return s
+}
+
+// Records returns the records required by Gandi Mail, except the SPF
+// and DMARC policies which are merged with the existing ones
+func Records(opts Options) []livedns.DomainRecord {
+	ttl := opts.TTL
+	if ttl == 0 {
+		ttl = DefaultTTL
+	}
+	record := func(name, rrtype string, values ...string) livedns.DomainRecord {
+		return livedns.DomainRecord{RrsetName: name, RrsetType: rrtype, RrsetTTL: ttl, RrsetValues: values}
+	}
+	return []livedns.DomainRecord{
+		record("@", "MX", "10 spool.mail.gandi.net.", "50 fb.mail.gandi.net."),
+		record("_imap._tcp", "SRV", "0 0 0 ."),
+		record("_imaps._tcp", "SRV", "0 1 993 mail.gandi.net."),
+		record("_pop3._tcp", "SRV", "0 0 0 ."),
+		record("_pop3s._tcp", "SRV", "10 1 995 mail.gandi.net."),
+		record("_submission._tcp", "SRV", "0 1 465 mail.gandi.net."),
+		record("_autodiscover._tcp", "SRV", "0 0 443 mail.gandi.net."),
+		record("webmail", "CNAME", "webmail.gandi.net."),
+		record("gm1._domainkey", "CNAME", "gm1.gandimail.net."),
+		record("gm2._domainkey", "CNAME", "gm2.gandimail.net."),
+		record("gm3._domainkey", "CNAME", "gm3.gandimail.net."),
+	}
+}
+
+// Verify returns the state of the records required by Gandi Mail in
+// the domain
+func Verify(client *livedns.LiveDNS, fqdn string, opts Options) ([]Check, error) {
+	records, err := client.GetDomainRecords(fqdn)
+	if err != nil {
+		return nil, err
+	}
+	return verify(records, opts), nil
+}
+
+func verify(records []livedns.DomainRecord, opts Options) []Check {
+	current := map[[2]string][]string{}
+	for _, record := range records {
+		current[[2]string{record.RrsetName, record.RrsetType}] = record.RrsetValues
+	}
+	var checks []Check
+	for _, record := range Records(opts) {
+		check := Check{Name: record.RrsetName, Type: record.RrsetType, Expected: record.RrsetValues}
+		check.Current = current[[2]string{record.RrsetName, record.RrsetType}]
+		var others []string
+		if record.RrsetType == "CNAME" {
+			others = otherTypes(records, record.RrsetName, "CNAME")
+		}
+		switch {
+		case len(others) > 0:
+			check.Status = StatusConflict
+			check.Message = fmt.Sprintf("the name has %s records, which cannot coexist with a CNAME", strings.Join(others, ", "))
+		case len(check.Current) == 0:
+			check.Status = StatusMissing
+		case sameValues(check.Current, check.Expected):
+			check.Status = StatusOK
+		default:
+			check.Status = StatusConflict
+		}
+		checks = append(checks, check)
+	}
+	checks = append(checks, verifySPF(current[[2]string{"@", "TXT"}]))
+	checks = append(checks, verifyDMARC(current[[2]string{"_dmarc", "TXT"}], opts))
+	return checks
+}
+
+// otherTypes returns the types of the rrsets of a name, except rrtype
+func otherTypes(records []livedns.DomainRecord, name, rrtype string) []string {
+	var types []string
+	for _, record := range records {
+		if record.RrsetName == name && record.RrsetType != rrtype {
+			types = append(types, record.RrsetType)
+		}
+	}
+	return types
+}
+
+// txtPolicies returns the texts of the TXT values with the prefix
+func txtPolicies(values []string, prefix string) []string {
+	var policies []string
+	for _, value := range values {
+		if text := txtText(value); strings.HasPrefix(strings.ToLower(text), prefix) {
+			policies = append(policies, text)
+		}
+	}
+	return policies
+}
+
+func txtText(value string) string {
+	parsed, err := rdata.Parse("TXT", value)
+	if err != nil {
+		return value
+	}
+	return parsed.(rdata.TXT).Text
+}
+
+func verifySPF(values []string) Check {
+	check := Check{Name: "@", Type: "TXT", Current: txtPolicies(values, "v=spf1")}
+	switch len(check.Current) {
+	case 0:
+		check.Status = StatusMissing
+		check.Expected = []string{"v=spf1 " + SPFInclude + " ?all"}
+	case 1:
+		check.Expected = []string{mergeSPF(check.Current[0])}
+		if check.Expected[0] == check.Current[0] {
+			check.Status = StatusOK
+		} else {
+			check.Status = StatusConflict
+			check.Message = "the SPF policy does not include " + SPFInclude
+		}
+	default:
+		check.Status = StatusConflict
+		check.Expected = []string{"a single SPF policy with " + SPFInclude}
+		check.Message = "the domain has several SPF policies"
+	}
+	return check
+}
+
+// mergeSPF adds the Gandi include to a SPF policy, before its all or
+// redirect term
+func mergeSPF(policy string) string {
+	terms := strings.Fields(policy)
+	for _, term := range terms {
+		if strings.EqualFold(term, SPFInclude) {
+			return policy
+		}
+	}
+	i := len(terms)
+	for j, term := range terms {
+		t := strings.ToLower(strings.TrimLeft(term, "+-~?"))
+		if t == "all" || strings.HasPrefix(t, "redirect=") {
+			i = j
+			break
+		}
+	}
+	merged := append([]string{}, terms[:i]...)
+	merged = append(merged, SPFInclude)
+	return strings.Join(append(merged, terms[i:]...), " ")
+}
+
+func verifyDMARC(values []string, opts Options) Check {
+	check := Check{Name: "_dmarc", Type: "TXT", Current: txtPolicies(values, "v=dmarc1")}
+	policy := opts.DMARCPolicy
+	if policy == "" {
+		policy = "none"
+	}
+	dmarc := "v=DMARC1; p=" + policy
+	if opts.DMARCReport != "" {
+		dmarc += "; rua=mailto:" + opts.DMARCReport
+	}
+	check.Expected = []string{dmarc}
+	switch len(check.Current) {
+	case 0:
+		check.Status = StatusMissing
+	case 1:
+		// Any existing policy is accepted
+		check.Status = StatusOK
+		check.Expected = check.Current
+	default:
+		check.Status = StatusConflict
+		check.Message = "the domain has several DMARC policies"
+	}
+	return check
+}
+
+// Apply creates the missing records required by Gandi Mail and adds
+// the Gandi include to the SPF policy. The conflicting records are
+// replaced when opts.Force is set. It returns the state of the
+// records before the changes, with the fixed ones marked.
+func Apply(client *livedns.LiveDNS, fqdn string, opts Options) ([]Check, error) {
+	records, err := client.GetDomainRecords(fqdn)
+	if err != nil {
+		return nil, err
+	}
+	ttl := opts.TTL
+	if ttl == 0 {
+		ttl = DefaultTTL
+	}
+	current := map[[2]string]livedns.DomainRecord{}
+	for _, record := range records {
+		current[[2]string{record.RrsetName, record.RrsetType}] = record
+	}
+	checks := verify(records, opts)
+	for i, check := range checks {
+		if check.Status == StatusOK {
+			continue
+		}
+		existing, exists := current[[2]string{check.Name, check.Type}]
+		var values []string
+		switch {
+		case check.Type == "TXT" && check.Status == StatusMissing:
+			// Other TXT values of the name are kept
+			values = append(append([]string{}, existing.RrsetValues...), rdata.TXT{Text: check.Expected[0]}.String())
+		case check.Type == "TXT" && check.Name == "@" && len(check.Current) == 1:
+			values = replaceSPF(existing.RrsetValues, check.Expected[0])
+		case check.Type == "TXT":
+			// Several SPF or DMARC policies need a manual fix
+			continue
+		case check.Status == StatusConflict && !opts.Force:
+			continue
+		default:
+			values = check.Expected
+		}
+		if check.Type == "CNAME" {
+			// The records conflicting with a CNAME are deleted first
+			for _, rrtype := range otherTypes(records, check.Name, "CNAME") {
+				if err := client.DeleteDomainRecord(fqdn, check.Name, rrtype); err != nil {
+					return checks, fmt.Errorf("Fail to delete the %s record of '%s' (error '%w')", rrtype, check.Name, err)
+				}
+			}
+		}
+		if exists {
+			_, err = client.UpdateDomainRecordByNameAndType(fqdn, check.Name, check.Type, existing.RrsetTTL, values)
+		} else {
+			_, err = client.CreateDomainRecord(fqdn, check.Name, check.Type, ttl, values)
+		}
+		if err != nil {
+			return checks, fmt.Errorf("Fail to set the %s record of '%s' (error '%w')", check.Type, check.Name, err)
+		}
+		checks[i].Fixed = true
+	}
+	return checks, nil
+}
+
+// replaceSPF replaces the SPF policy among the TXT values
+func replaceSPF(values []string, spf string) []string {
+	replaced := make([]string, 0, len(values))
+	for _, value := range values {
+		if strings.HasPrefix(strings.ToLower(txtText(value)), "v=spf1") {
+			value = rdata.TXT{Text: spf}.String()
+		}
+		replaced = append(replaced, value)
+	}
+	return replaced
+}
+
+// sameValues compares values regardless of their order, case and
+// spacing
+func sameValues(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	normalized := map[string]int{}
+	for _, v := range a {
+		normalized[normalize(v)]++
+	}
+	for _, v := range b {
+		n := normalize(v)
+		if normalized[n] == 0 {
+			return false
+		}
+		normalized[n]--
+	}
+	return true
+}
+
+func normalize(value string) string {
+	return strings.ToLower(strings.Join(strings.Fields(value), " "))
+}
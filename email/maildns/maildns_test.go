@@ -0,0 +1,125 @@
+package maildns_test
+
+import (
+	"testing"
+
+	"github.com/go-gandi/go-gandi/config"
+	"github.com/go-gandi/go-gandi/email/maildns"
+	"github.com/go-gandi/go-gandi/livedns"
+	"gopkg.in/h2non/gock.v1"
+)
+
+func TestApply(t *testing.T) {
+	defer gock.Off()
+	gock.New("https://api.gandi.net/v5/").
+		Get("livedns/domains/example.com/records").
+		Reply(200).
+		JSON([]livedns.DomainRecord{
+			{RrsetName: "@", RrsetType: "MX", RrsetTTL: 300, RrsetValues: []string{"1 aspmx.l.google.com."}},
+			{RrsetName: "@", RrsetType: "TXT", RrsetTTL: 300, RrsetValues: []string{`"google-site-verification=abc"`, `"v=spf1 include:_spf.google.com ~all"`}},
+			{RrsetName: "webmail", RrsetType: "CNAME", RrsetTTL: 300, RrsetValues: []string{"WEBMAIL.gandi.net."}},
+			{RrsetName: "_dmarc", RrsetType: "TXT", RrsetTTL: 300, RrsetValues: []string{`"v=DMARC1; p=reject"`}},
+		})
+	gock.New("https://api.gandi.net/v5/").
+		Put("livedns/domains/example.com/records/@/TXT").
+		JSON(livedns.DomainRecord{RrsetType: "TXT", RrsetTTL: 300, RrsetValues: []string{
+			`"google-site-verification=abc"`,
+			`"v=spf1 include:_spf.google.com include:_mailcust.gandi.net ~all"`,
+		}}).
+		Reply(201).
+		JSON(map[string]string{"message": "DNS Record Created"})
+	// The 6 SRV and the 3 DKIM records
+	gock.New("https://api.gandi.net/v5/").
+		Post("livedns/domains/example.com/records").
+		Times(9).
+		Reply(201).
+		JSON(map[string]string{"message": "DNS Record Created"})
+
+	checks, err := maildns.Apply(livedns.New(config.Config{}), "example.com", maildns.Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	statuses := map[string]maildns.Check{}
+	for _, check := range checks {
+		statuses[check.Name+" "+check.Type] = check
+	}
+	if c := statuses["@ MX"]; c.Status != maildns.StatusConflict || c.Fixed {
+		t.Errorf("The Google MX should be reported but kept: %v", c)
+	}
+	if c := statuses["@ TXT"]; c.Status != maildns.StatusConflict || !c.Fixed {
+		t.Errorf("The SPF policy should be merged: %v", c)
+	}
+	if c := statuses["webmail CNAME"]; c.Status != maildns.StatusOK {
+		t.Errorf("The webmail CNAME should be ok: %v", c)
+	}
+	if c := statuses["_dmarc TXT"]; c.Status != maildns.StatusOK {
+		t.Errorf("An existing DMARC policy should be kept: %v", c)
+	}
+	if c := statuses["gm1._domainkey CNAME"]; c.Status != maildns.StatusMissing || !c.Fixed {
+		t.Errorf("The DKIM record should be created: %v", c)
+	}
+	if !gock.IsDone() {
+		t.Fatal("All the expected requests have not been sent")
+	}
+}
+
+func TestApplyWebmailConflict(t *testing.T) {
+	defer gock.Off()
+	records := []livedns.DomainRecord{
+		{RrsetName: "webmail", RrsetType: "A", RrsetTTL: 300, RrsetValues: []string{"192.0.2.1"}},
+	}
+	for _, record := range maildns.Records(maildns.Options{}) {
+		if record.RrsetName != "webmail" {
+			records = append(records, record)
+		}
+	}
+	gock.New("https://api.gandi.net/v5/").
+		Get("livedns/domains/example.com/records").
+		Reply(200).
+		JSON(records)
+	// The SPF and DMARC policies are missing
+	gock.New("https://api.gandi.net/v5/").
+		Post("livedns/domains/example.com/records").
+		Times(2).
+		Reply(201).
+		JSON(map[string]string{"message": "DNS Record Created"})
+
+	// The A record is reported but kept
+	checks, err := maildns.Apply(livedns.New(config.Config{}), "example.com", maildns.Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, check := range checks {
+		if check.Name == "webmail" && (check.Status != maildns.StatusConflict || check.Fixed) {
+			t.Errorf("The webmail A record should conflict with the CNAME: %v", check)
+		}
+	}
+	if !gock.IsDone() {
+		t.Fatal("All the expected requests have not been sent")
+	}
+
+	// It is replaced when forced
+	gock.New("https://api.gandi.net/v5/").
+		Get("livedns/domains/example.com/records").
+		Reply(200).
+		JSON(records)
+	gock.New("https://api.gandi.net/v5/").
+		Delete("livedns/domains/example.com/records/webmail/A").
+		Reply(204)
+	gock.New("https://api.gandi.net/v5/").
+		Post("livedns/domains/example.com/records").
+		JSON(livedns.DomainRecord{RrsetName: "webmail", RrsetType: "CNAME", RrsetTTL: maildns.DefaultTTL, RrsetValues: []string{"webmail.gandi.net."}}).
+		Reply(201).
+		JSON(map[string]string{"message": "DNS Record Created"})
+	gock.New("https://api.gandi.net/v5/").
+		Post("livedns/domains/example.com/records").
+		Times(2).
+		Reply(201).
+		JSON(map[string]string{"message": "DNS Record Created"})
+	if _, err := maildns.Apply(livedns.New(config.Config{}), "example.com", maildns.Options{Force: true}); err != nil {
+		t.Fatal(err)
+	}
+	if !gock.IsDone() {
+		t.Fatal("All the expected requests have not been sent")
+	}
+}
@@ -0,0 +1,101 @@
+package email
+
+import (
+	"crypto/rand"
+	"fmt"
+	"math/big"
+	"unicode"
+)
+
+// Password policy of the Gandi mailboxes
+const (
+	PasswordMinLength = 9
+	PasswordMaxLength = 200
+	// PasswordMinDigits is the minimum number of digits
+	PasswordMinDigits = 3
+)
+
+// DefaultPasswordLength is the length of the generated passwords
+const DefaultPasswordLength = 20
+
+const (
+	passwordLower   = "abcdefghijkmnopqrstuvwxyz"
+	passwordUpper   = "ABCDEFGHJKLMNPQRSTUVWXYZ"
+	passwordDigits  = "23456789"
+	passwordSymbols = "-_.+=!@%"
+)
+
+// ValidatePassword checks a password against the policy of the Gandi
+// mailboxes: between PasswordMinLength and PasswordMaxLength
+// characters, with at least an uppercase letter, a lowercase letter
+// and PasswordMinDigits digits
+func ValidatePassword(password string) error {
+	var upper, lower, digits int
+	for _, r := range password {
+		switch {
+		case unicode.IsUpper(r):
+			upper++
+		case unicode.IsLower(r):
+			lower++
+		case unicode.IsDigit(r):
+			digits++
+		}
+	}
+	length := len([]rune(password))
+	switch {
+	case length < PasswordMinLength || length > PasswordMaxLength:
+		return fmt.Errorf("The password must have between %d and %d characters", PasswordMinLength, PasswordMaxLength)
+	case upper == 0 || lower == 0:
+		return fmt.Errorf("The password must have uppercase and lowercase letters")
+	case digits < PasswordMinDigits:
+		return fmt.Errorf("The password must have at least %d digits", PasswordMinDigits)
+	}
+	return nil
+}
+
+// GeneratePassword returns a random password of the given length,
+// DefaultPasswordLength if zero, meeting the policy of the Gandi
+// mailboxes. Ambiguous characters such as l, 1, O and 0 are not used.
+func GeneratePassword(length int) (string, error) {
+	if length == 0 {
+		length = DefaultPasswordLength
+	}
+	if length < PasswordMinLength || length > PasswordMaxLength {
+		return "", fmt.Errorf("The password length must be between %d and %d", PasswordMinLength, PasswordMaxLength)
+	}
+	// The required characters come first and are shuffled with the
+	// others
+	classes := []string{passwordUpper, passwordLower}
+	for i := 0; i < PasswordMinDigits; i++ {
+		classes = append(classes, passwordDigits)
+	}
+	all := passwordLower + passwordUpper + passwordDigits + passwordSymbols
+	password := make([]byte, length)
+	for i := range password {
+		set := all
+		if i < len(classes) {
+			set = classes[i]
+		}
+		c, err := randomIndex(len(set))
+		if err != nil {
+			return "", err
+		}
+		password[i] = set[c]
+	}
+	for i := len(password) - 1; i > 0; i-- {
+		j, err := randomIndex(i + 1)
+		if err != nil {
+			return "", err
+		}
+		password[i], password[j] = password[j], password[i]
+	}
+	return string(password), nil
+}
+
+func randomIndex(n int) (int, error) {
+	i, err := rand.Int(rand.Reader, big.NewInt(int64(n)))
+	if err != nil {
+		return 0, fmt.Errorf("Fail to generate a password (error '%w')", err)
+	}
+	return int(i.Int64()), nil
+}
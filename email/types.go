@@ -46,10 +46,72 @@ type MailboxResponse struct {
 	Login       string    `json:"login"`
 	MailboxType string    `json:"mailbox_type"`
 	QuotaUsed   int       `json:"quota_used"`
-	Responder   struct {
-		Message string `json:"message"`
-		Enabled bool   `json:"enabled"`
-	} `json:"responder"`
+	Responder   Responder `json:"responder"`
+}
+
+// Mailbox types
+const (
+	MailboxStandard = "standard"
+	MailboxPremium  = "premium"
+)
+
+// Responder is the automatic reply sent by a mailbox. StartsAt and
+// EndsAt optionally limit the period during which it is sent.
+type Responder struct {
+	Enabled  bool       `json:"enabled"`
+	Message  string     `json:"message"`
+	StartsAt *time.Time `json:"starts_at,omitempty"`
+	EndsAt   *time.Time `json:"ends_at,omitempty"`
+}
+
+// responderRequest sets the responder of a mailbox
+type responderRequest struct {
+	Responder Responder `json:"responder"`
+}
+
+// antispamRequest enables or disables the antispam of a mailbox
+type antispamRequest struct {
+	Antispam bool `json:"antispam"`
+}
+
+// renewRequest renews a mailbox for a number of months
+type renewRequest struct {
+	Duration int `json:"duration"`
+}
+
+// upgradeRequest changes the type of a mailbox
+type upgradeRequest struct {
+	MailboxType string `json:"mailbox_type"`
+}
+
+// Slot is a mailbox slot bought for a domain. A mailbox of the same
+// type can be created for each free slot.
+type Slot struct {
+	ID          int    `json:"id"`
+	MailboxType string `json:"mailbox_type"`
+	Status      string `json:"status"`
+	// Capacity is the storage of the mailbox in bytes
+	Capacity int64 `json:"capacity"`
+	// Refundable tells if the slot is refunded when deleted, for
+	// RefundAmount in RefundCurrency
+	Refundable     bool      `json:"refundable"`
+	RefundAmount   float64   `json:"refund_amount,omitempty"`
+	RefundCurrency string    `json:"refund_currency,omitempty"`
+	CreatedAt      time.Time `json:"created_at"`
+	Href           string    `json:"href"`
+}
+
+// createSlotRequest buys a slot
+type createSlotRequest struct {
+	MailboxType string `json:"mailbox_type"`
+}
+
+// SlotUsage summarizes the slots of a domain for a mailbox type
+type SlotUsage struct {
+	MailboxType string `json:"mailbox_type"`
+	Total       int    `json:"total"`
+	Used        int    `json:"used"`
+	Free        int    `json:"free"`
 }
 
 // CreateEmailRequest create mailbox request
@@ -60,11 +122,21 @@ type CreateEmailRequest struct {
 	Aliases     []string `json:"aliases,omitempty"`
 }
 
-// UpdateEmailRequest update mailbox request
+// UpdateEmailRequest update mailbox request. Empty fields are left
+// unchanged, so aliases are cleared with SetAliases or a MailboxPatch.
 type UpdateEmailRequest struct {
 	Login    string   `json:"login,omitempty"`
 	Password string   `json:"password,omitempty"`
-	Aliases  []string `json:"aliases"`
+	Aliases  []string `json:"aliases,omitempty"`
+}
+
+// MailboxPatch updates the fields of a mailbox which are not nil,
+// leaving the others untouched. A non-nil empty Aliases removes all
+// the aliases.
+type MailboxPatch struct {
+	Login    *string   `json:"login,omitempty"`
+	Password *string   `json:"password,omitempty"`
+	Aliases  *[]string `json:"aliases,omitempty"`
 }
 
 // CreateForwardRequest structure for forwarding request
@@ -73,13 +145,18 @@ type CreateForwardRequest struct {
 	Destinations []string `json:"destinations"`
 }
 
-// GetForwardRequest structure for forwarding responses
-type GetForwardRequest struct {
+// ForwardResponse describes a forwarding of an address of a domain
+type ForwardResponse struct {
 	Source       string   `json:"source"`
 	Destinations []string `json:"destinations"`
 	Href         string   `json:"href"`
 }
 
+// GetForwardRequest structure for forwarding responses
+//
+// Deprecated: use ForwardResponse
+type GetForwardRequest = ForwardResponse
+
 // UpdateForwardRequest structure for updating forwarding
 type UpdateForwardRequest struct {
 	Destinations []string `json:"destinations"`
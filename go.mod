@@ -4,9 +4,15 @@ go 1.17
 
 require (
 	github.com/alecthomas/kong v0.2.2
+	github.com/miekg/dns v1.1.50
 	github.com/peterhellberg/link v1.1.0
+	golang.org/x/crypto v0.0.0-20220722155217-630584e8d5aa
+	golang.org/x/net v0.0.0-20211112202133-69e39bad7dc2
+	golang.org/x/term v0.0.0-20210615171337-6886f2dfbf5b
 	gopkg.in/h2non/gock.v1 v1.1.2
+	gopkg.in/yaml.v2 v2.4.0
 	moul.io/http2curl v1.0.0
+	software.sslmate.com/src/go-pkcs12 v0.2.0
 )
 
 require (
@@ -14,4 +20,9 @@ require (
 	github.com/pkg/errors v0.8.1 // indirect
 	github.com/smartystreets/goconvey v1.7.2 // indirect
 	github.com/stretchr/testify v1.4.0 // indirect
+	golang.org/x/mod v0.4.2 // indirect
+	golang.org/x/sys v0.0.0-20210630005230-0f9fa26af87c // indirect
+	golang.org/x/text v0.3.6 // indirect
+	golang.org/x/tools v0.1.6-0.20210726203631-07bc1bf47fb2 // indirect
+	golang.org/x/xerrors v0.0.0-20200804184101-5ec99f83aff1 // indirect
 )
@@ -131,6 +131,37 @@ func (g *Gandi) askGandiCollection(method, path string, params interface{}) (htt
 	return header, elements, nil
 }
 
+// PostText issues a POST request whose body is sent as text/plain
+// instead of JSON. Response data is written to the recipient.
+// Returns the response headers and any error
+func (g *Gandi) PostText(path string, text []byte, recipient interface{}) (http.Header, error) {
+	return g.askGandiText(http.MethodPost, path, text, recipient)
+}
+
+// PutText issues a PUT request whose body is sent as text/plain
+// instead of JSON. Response data is written to the recipient.
+// Returns the response headers and any error
+func (g *Gandi) PutText(path string, text []byte, recipient interface{}) (http.Header, error) {
+	return g.askGandiText(http.MethodPut, path, text, recipient)
+}
+
+// DryRun returns true if the client has been configured to send
+// requests in dry run mode.
+func (g *Gandi) DryRun() bool {
+	return g.dryRun
+}
+
+func (g *Gandi) askGandiText(method, path string, text []byte, recipient interface{}) (http.Header, error) {
+	header, body, err := g.doRequest(method, path, text, "text/plain", nil)
+	if err != nil {
+		return nil, err
+	}
+	if recipient == nil {
+		return header, nil
+	}
+	return header, json.Unmarshal(body, &recipient)
+}
+
 // GetBytes issues a GET request but does not attempt to parse any response into JSON.
 // It returns the response headers, a byteslice of the response, and any error
 func (g *Gandi) GetBytes(path string, params interface{}) (http.Header, []byte, error) {
@@ -142,22 +173,34 @@ func (g *Gandi) GetBytes(path string, params interface{}) (http.Header, []byte,
 // the response is not success, the returned error is a RequestError
 // (which contains the HTTP StatusCode).
 func (g *Gandi) doAskGandi(method, path string, p interface{}, extraHeaders [][2]string) (http.Header, []byte, error) {
-	var (
-		err error
-		req *http.Request
-	)
 	params, err := json.Marshal(p)
 	if err != nil {
 		return nil, nil, fmt.Errorf("Fail to json.Marshal request params (error '%w')", err)
 	}
+	if string(params) == "null" {
+		params = nil
+	}
+	return g.doRequest(method, path, params, "application/json", extraHeaders)
+}
+
+// doRequest sends the body as is, with the given content type
+func (g *Gandi) doRequest(method, path string, params []byte, contentType string, extraHeaders [][2]string) (http.Header, []byte, error) {
+	var (
+		err error
+		req *http.Request
+	)
 	client := &http.Client{
 		Timeout: g.timeout,
 	}
 	suffix := ""
 	if len(g.sharingID) != 0 {
-		suffix += "?sharing_id=" + g.sharingID
+		separator := "?"
+		if strings.Contains(path, "?") {
+			separator = "&"
+		}
+		suffix += separator + "sharing_id=" + g.sharingID
 	}
-	if params != nil && string(params) != "null" {
+	if params != nil {
 		req, err = http.NewRequest(method, g.endpoint+path+suffix, bytes.NewReader(params))
 	} else {
 		req, err = http.NewRequest(method, g.endpoint+path+suffix, nil)
@@ -170,7 +213,7 @@ func (g *Gandi) doAskGandi(method, path string, p interface{}, extraHeaders [][2
 	} else {
 		req.Header.Add("Authorization", "Apikey "+g.apikey)
 	}
-	req.Header.Add("Content-Type", "application/json")
+	req.Header.Add("Content-Type", contentType)
 	if g.dryRun {
 		req.Header.Add("Dry-Run", "1")
 	}
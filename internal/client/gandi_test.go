@@ -110,3 +110,38 @@ func TestRequestErrorNotJson(t *testing.T) {
 		t.Fatalf("Invalid error for non Json response code")
 	}
 }
+
+func TestTextRequests(t *testing.T) {
+	defer gock.Off()
+	zone := "www 300 IN A 192.0.2.1\n"
+	gock.New("https://api.gandi.net/v5/").
+		Put("/livedns/domains/example.com/records").
+		MatchHeader("Content-Type", "text/plain").
+		BodyString(zone).
+		Reply(201).
+		JSON(types.StandardResponse{Message: "Zone imported"})
+	gock.New("https://api.gandi.net/v5/").
+		Post("/livedns/domains/example.com/records").
+		MatchHeader("Content-Type", "text/plain").
+		BodyString(zone).
+		Reply(201).
+		JSON(types.StandardResponse{Message: "Zone merged"})
+	client := New("", "", "https://api.gandi.net", "", false, false, 1*time.Second)
+
+	var response types.StandardResponse
+	if _, err := client.PutText("livedns/domains/example.com/records", []byte(zone), &response); err != nil {
+		t.Fatal(err)
+	}
+	if response.Message != "Zone imported" {
+		t.Fatalf("Unexpected response %+v", response)
+	}
+	if _, err := client.PostText("livedns/domains/example.com/records", []byte(zone), &response); err != nil {
+		t.Fatal(err)
+	}
+	if response.Message != "Zone merged" {
+		t.Fatalf("Unexpected response %+v", response)
+	}
+	if !gock.IsDone() {
+		t.Fatal("All the expected requests have not been sent")
+	}
+}
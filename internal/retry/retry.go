@@ -0,0 +1,33 @@
+// Package retry runs read-modify-write cycles again when a concurrent
+// writer is detected
+package retry
+
+import (
+	"math/rand"
+	"time"
+)
+
+const (
+	// Attempts is the number of cycles before giving up
+	Attempts = 5
+	// Backoff is the base delay between two cycles
+	Backoff = 200 * time.Millisecond
+)
+
+// Do calls cycle until it returns true or an error, at most Attempts
+// times. The delay between two calls grows linearly with a random
+// jitter, so that concurrent writers do not retry in lockstep. It
+// returns false if no cycle succeeded.
+func Do(cycle func() (bool, error)) (bool, error) {
+	for attempt := 0; attempt < Attempts; attempt++ {
+		if attempt > 0 {
+			delay := Backoff * time.Duration(attempt)
+			time.Sleep(delay + time.Duration(rand.Int63n(int64(delay))))
+		}
+		ok, err := cycle()
+		if ok || err != nil {
+			return ok, err
+		}
+	}
+	return false, nil
+}
@@ -0,0 +1,123 @@
+// Package acme solves ACME DNS-01 challenges (RFC 8555) with LiveDNS.
+//
+// The challenge value is added to the _acme-challenge TXT rrset
+// instead of replacing it, so that several validations of the same
+// name can run in parallel, as for a certificate covering both
+// example.com and *.example.com.
+package acme
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/go-gandi/go-gandi/config"
+	"github.com/go-gandi/go-gandi/livedns"
+)
+
+// Solver presents and cleans up the DNS records of a DNS-01
+// challenge. It has the same methods as the challenge.Provider
+// interface of lego.
+type Solver interface {
+	Present(domain, token, keyAuth string) error
+	CleanUp(domain, token, keyAuth string) error
+}
+
+// DefaultTTL is the TTL of the challenge records, short enough to let
+// a failed validation be retried quickly
+const DefaultTTL = 300
+
+// ChallengePrefix is the label under which DNS-01 challenges are
+// published
+const ChallengePrefix = "_acme-challenge"
+
+// Provider is a Solver backed by LiveDNS
+type Provider struct {
+	client *livedns.LiveDNS
+	// TTL of the created challenge records
+	TTL int
+
+	zones *livedns.ZoneFinder
+
+	mu sync.Mutex
+	// rrsets serializes the writes of the challenges of a name, such
+	// as those of example.com and *.example.com, which share the
+	// same rrset
+	rrsets map[string]*sync.Mutex
+}
+
+var _ Solver = (*Provider)(nil)
+
+// New returns a Provider using a LiveDNS client configured from config
+func New(config config.Config) *Provider {
+	return NewFromLiveDNS(livedns.New(config))
+}
+
+// NewFromLiveDNS returns a Provider using an existing LiveDNS client
+func NewFromLiveDNS(client *livedns.LiveDNS) *Provider {
+	return &Provider{client: client, TTL: DefaultTTL, zones: livedns.NewZoneFinder(client), rrsets: map[string]*sync.Mutex{}}
+}
+
+// ChallengeRecord returns the fully qualified name and the value of
+// the TXT record validating the domain for the key authorization
+func ChallengeRecord(domain, keyAuth string) (fqdn, value string) {
+	domain = strings.TrimPrefix(strings.TrimSuffix(domain, "."), "*.")
+	digest := sha256.Sum256([]byte(keyAuth))
+	return ChallengePrefix + "." + domain + ".", base64.RawURLEncoding.EncodeToString(digest[:])
+}
+
+// Present adds the challenge value to the TXT rrset of the domain
+func (p *Provider) Present(domain, token, keyAuth string) error {
+	fqdn, value := ChallengeRecord(domain, keyAuth)
+	zone, name, err := p.FindZone(fqdn)
+	if err != nil {
+		return err
+	}
+	defer p.lockRrset(zone, name)()
+	if _, err := p.client.AddRecordValues(zone, name, "TXT", p.TTL, []string{quote(value)}); err != nil {
+		return fmt.Errorf("Fail to present the challenge for '%s' (error '%w')", domain, err)
+	}
+	return nil
+}
+
+// CleanUp removes the challenge value from the TXT rrset of the
+// domain, leaving the values of the other pending validations
+func (p *Provider) CleanUp(domain, token, keyAuth string) error {
+	fqdn, value := ChallengeRecord(domain, keyAuth)
+	zone, name, err := p.FindZone(fqdn)
+	if err != nil {
+		return err
+	}
+	defer p.lockRrset(zone, name)()
+	if _, err := p.client.RemoveRecordValues(zone, name, "TXT", []string{quote(value)}); err != nil {
+		return fmt.Errorf("Fail to clean up the challenge for '%s' (error '%w')", domain, err)
+	}
+	return nil
+}
+
+// FindZone returns the LiveDNS domain hosting a name, and the name of
+// the record relative to this domain, see livedns.ZoneFinder
+func (p *Provider) FindZone(fqdn string) (zone, name string, err error) {
+	return p.zones.FindZone(fqdn)
+}
+
+// lockRrset locks the challenge rrset of a name and returns the
+// function unlocking it
+func (p *Provider) lockRrset(zone, name string) func() {
+	key := name + "." + zone
+	p.mu.Lock()
+	m, ok := p.rrsets[key]
+	if !ok {
+		m = &sync.Mutex{}
+		p.rrsets[key] = m
+	}
+	p.mu.Unlock()
+	m.Lock()
+	return m.Unlock
+}
+
+func quote(value string) string {
+	return `"` + value + `"`
+}
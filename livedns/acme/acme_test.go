@@ -0,0 +1,77 @@
+package acme_test
+
+import (
+	"testing"
+
+	"github.com/go-gandi/go-gandi/config"
+	"github.com/go-gandi/go-gandi/livedns"
+	"github.com/go-gandi/go-gandi/livedns/acme"
+	"gopkg.in/h2non/gock.v1"
+)
+
+func notFound(path string) {
+	gock.New("https://api.gandi.net/v5/").
+		Get(path).
+		Reply(404).
+		JSON(map[string]interface{}{"code": 404, "message": "Domain not found"})
+}
+
+func TestChallengeRecord(t *testing.T) {
+	fqdn, value := acme.ChallengeRecord("*.example.com", "token.thumbprint")
+	if fqdn != "_acme-challenge.example.com." {
+		t.Fatalf("Unexpected challenge name %s", fqdn)
+	}
+	if len(value) != 43 {
+		t.Fatalf("The challenge value should be an unpadded base64url SHA-256 digest (got %s)", value)
+	}
+}
+
+func TestPresentAddsToExistingRrset(t *testing.T) {
+	defer gock.Off()
+	_, value := acme.ChallengeRecord("www.example.com", "keyauth")
+	notFound("livedns/domains/_acme-challenge.www.example.com$")
+	notFound("livedns/domains/www.example.com$")
+	gock.New("https://api.gandi.net/v5/").
+		Get("livedns/domains/example.com$").
+		Reply(200).
+		JSON(livedns.Domain{FQDN: "example.com"})
+	gock.New("https://api.gandi.net/v5/").
+		Get("livedns/domains/example.com/records/_acme-challenge.www/TXT").
+		Reply(200).
+		JSON(livedns.DomainRecord{RrsetTTL: 300, RrsetValues: []string{`"other"`}})
+	gock.New("https://api.gandi.net/v5/").
+		Put("livedns/domains/example.com/records/_acme-challenge.www/TXT").
+		JSON(livedns.DomainRecord{RrsetType: "TXT", RrsetTTL: 300, RrsetValues: []string{`"other"`, `"` + value + `"`}}).
+		Reply(201).
+		JSON(map[string]string{"message": "DNS Record Created"})
+	gock.New("https://api.gandi.net/v5/").
+		Get("livedns/domains/example.com/records/_acme-challenge.www/TXT").
+		Reply(200).
+		JSON(livedns.DomainRecord{RrsetTTL: 300, RrsetValues: []string{`"other"`, `"` + value + `"`}})
+
+	provider := acme.New(config.Config{})
+	if err := provider.Present("www.example.com", "token", "keyauth"); err != nil {
+		t.Fatal(err)
+	}
+	if !gock.IsDone() {
+		t.Fatal("All the expected requests have not been sent")
+	}
+}
+
+func TestFindZoneForbidden(t *testing.T) {
+	defer gock.Off()
+	notFound("livedns/domains/_acme-challenge.example.com$")
+	gock.New("https://api.gandi.net/v5/").
+		Get("livedns/domains/example.com$").
+		Reply(403).
+		JSON(map[string]interface{}{"code": 403, "message": "Access was denied to this resource"})
+
+	// A permission error is not mistaken for a missing zone
+	_, _, err := acme.New(config.Config{}).FindZone("_acme-challenge.example.com.")
+	if err == nil {
+		t.Fatal("The permission error should be returned")
+	}
+	if !gock.IsDone() {
+		t.Fatal("All the expected requests have not been sent")
+	}
+}
@@ -1,67 +1,167 @@
 package livedns
 
+import (
+	"fmt"
+
+	"github.com/go-gandi/go-gandi/types"
+)
+
+// GetTSIGKeys retrieves all the TSIG keys for the account
+func (g *LiveDNS) GetTSIGKeys() (response []TSIGKey, err error) {
+	_, err = g.client.Get("axfr/tsig", nil, &response)
+	return
+}
+
+// GetTSIGKey retrieves the specified TSIG key
+func (g *LiveDNS) GetTSIGKey(id string) (response TSIGKey, err error) {
+	_, err = g.client.Get("axfr/tsig/"+id, nil, &response)
+	return
+}
+
+// GetTSIGKeyConfig returns a sample configuration of a nameserver
+// using the TSIG key to transfer zones from the LiveDNS servers. The
+// sample is generated by the API; see RenderSecondaryConfig to
+// generate a configuration covering several zones.
+func (g *LiveDNS) GetTSIGKeyConfig(id string, format SecondaryFormat) ([]byte, error) {
+	if !format.valid() {
+		return nil, fmt.Errorf("Unknown secondary configuration format '%s'", format)
+	}
+	_, content, err := g.client.GetBytes("axfr/tsig/"+id+"/config/"+string(format), nil)
+	return content, err
+}
+
+// CreateAccountTSIGKey creates a TSIG key. It must be associated
+// with the domains it transfers, see AssociateTSIGKeyWithDomain.
+func (g *LiveDNS) CreateAccountTSIGKey() (response TSIGKey, err error) {
+	_, err = g.client.Post("axfr/tsig", nil, &response)
+	return
+}
+
+// CreateTSIGKey creates a TSIG key. The fqdn is ignored: the key is
+// not associated with the domain.
+//
+// Deprecated: use CreateAccountTSIGKey
+func (g *LiveDNS) CreateTSIGKey(fqdn string) (response TSIGKey, err error) {
+	return g.CreateAccountTSIGKey()
+}
+
+// GetDomainTSIGKeys retrieves the TSIG keys associated with a domain
+func (g *LiveDNS) GetDomainTSIGKeys(fqdn string) (response []TSIGKey, err error) {
+	_, err = g.client.Get("domains/"+fqdn+"/axfr/tsig", nil, &response)
+	return
+}
+
+// AssociateTSIGKeyWithDomain allows the specified TSIG key to transfer
+// the zone of a domain
+func (g *LiveDNS) AssociateTSIGKeyWithDomain(fqdn string, id string) (response types.StandardResponse, err error) {
+	_, err = g.client.Put("domains/"+fqdn+"/axfr/tsig/"+id, nil, &response)
+	return
+}
+
+// RemoveTSIGKeyFromDomain removes the association between the
+// specified TSIG key and a domain
+func (g *LiveDNS) RemoveTSIGKeyFromDomain(fqdn string, id string) (err error) {
+	_, err = g.client.Delete("domains/"+fqdn+"/axfr/tsig/"+id, nil, nil)
+	return
+}
+
+// AddSecondary allows a secondary nameserver, identified by its IP
+// address, to transfer the zone of a domain
+func (g *LiveDNS) AddSecondary(fqdn, host string) (err error) {
+	_, err = g.client.Put("domains/"+fqdn+"/axfr/slaves/"+host, nil, nil)
+	return
+}
+
+// ListSecondaries lists the secondary nameservers allowed to transfer
+// the zone of a domain
+func (g *LiveDNS) ListSecondaries(fqdn string) (secondaries []string, err error) {
+	_, err = g.client.Get("domains/"+fqdn+"/axfr/slaves", nil, &secondaries)
+	return
+}
+
+// RemoveSecondary removes a secondary nameserver from a domain
+func (g *LiveDNS) RemoveSecondary(fqdn, host string) (err error) {
+	_, err = g.client.Delete("domains/"+fqdn+"/axfr/slaves/"+host, nil, nil)
+	return
+}
+
 // ListTsigs lists all tsigs
+//
+// Deprecated: use GetTSIGKeys
 func (g *LiveDNS) ListTsigs() (tsigs []Tsig, err error) {
 	_, err = g.client.Get("axfr/tsig", nil, &tsigs)
 	return
 }
 
 // GetTsig lists more tsig details
+//
+// Deprecated: use GetTSIGKey
 func (g *LiveDNS) GetTsig(uuid string) (tsig Tsig, err error) {
 	_, err = g.client.Get("axfr/tsig/"+uuid, nil, &tsig)
 	return
 }
 
 // GetTsigBIND shows a BIND nameserver config, and includes the nameservers available for zone transfers
+//
+// Deprecated: use GetTSIGKeyConfig
 func (g *LiveDNS) GetTsigBIND(uuid string) ([]byte, error) {
-	_, content, err := g.client.GetBytes("axfr/tsig/"+uuid+"/config/bind", nil)
-	return content, err
+	return g.GetTSIGKeyConfig(uuid, SecondaryBIND)
 }
 
 // GetTsigPowerDNS shows a PowerDNS nameserver config, and includes the nameservers available for zone transfers
+//
+// Deprecated: use GetTSIGKeyConfig
 func (g *LiveDNS) GetTsigPowerDNS(uuid string) ([]byte, error) {
-	_, content, err := g.client.GetBytes("axfr/tsig/"+uuid+"/config/powerdns", nil)
-	return content, err
+	return g.GetTSIGKeyConfig(uuid, SecondaryPowerDNS)
 }
 
 // GetTsigNSD shows a NSD nameserver config, and includes the nameservers available for zone transfers
+//
+// Deprecated: use GetTSIGKeyConfig
 func (g *LiveDNS) GetTsigNSD(uuid string) ([]byte, error) {
-	_, content, err := g.client.GetBytes("axfr/tsig/"+uuid+"/config/nsd", nil)
-	return content, err
+	return g.GetTSIGKeyConfig(uuid, SecondaryNSD)
 }
 
 // GetTsigKnot shows a Knot nameserver config, and includes the nameservers available for zone transfers
+//
+// Deprecated: use GetTSIGKeyConfig
 func (g *LiveDNS) GetTsigKnot(uuid string) ([]byte, error) {
-	_, content, err := g.client.GetBytes("axfr/tsig/"+uuid+"/config/knot", nil)
-	return content, err
+	return g.GetTSIGKeyConfig(uuid, SecondaryKnot)
 }
 
 // CreateTsig creates a tsig
+//
+// Deprecated: use CreateTSIGKey
 func (g *LiveDNS) CreateTsig() (tsig Tsig, err error) {
 	_, err = g.client.Post("axfr/tsig", nil, &tsig)
 	return
 }
 
 // AddTsigToDomain adds a tsig to a domain
+//
+// Deprecated: use AssociateTSIGKeyWithDomain
 func (g *LiveDNS) AddTsigToDomain(fqdn, uuid string) (err error) {
-	_, err = g.client.Put("domains/"+fqdn+"/axfr/tsig/"+uuid, nil, nil)
+	_, err = g.AssociateTSIGKeyWithDomain(fqdn, uuid)
 	return
 }
 
 // AddSlaveToDomain adds a slave to a domain
+//
+// Deprecated: use AddSecondary
 func (g *LiveDNS) AddSlaveToDomain(fqdn, host string) (err error) {
-	_, err = g.client.Put("domains/"+fqdn+"/axfr/slaves/"+host, nil, nil)
-	return
+	return g.AddSecondary(fqdn, host)
 }
 
 // ListSlavesInDomain lists slaves in a domain
+//
+// Deprecated: use ListSecondaries
 func (g *LiveDNS) ListSlavesInDomain(fqdn string) (slaves []string, err error) {
-	_, err = g.client.Get("domains/"+fqdn+"/axfr/slaves", nil, &slaves)
-	return
+	return g.ListSecondaries(fqdn)
 }
 
 // DelSlaveFromDomain removes a slave from a domain
+//
+// Deprecated: use RemoveSecondary
 func (g *LiveDNS) DelSlaveFromDomain(fqdn, host string) (err error) {
-	_, err = g.client.Delete("domains/"+fqdn+"/axfr/slaves/"+host, nil, nil)
-	return
+	return g.RemoveSecondary(fqdn, host)
 }
@@ -0,0 +1,171 @@
+// Package ddns keeps the A and AAAA records of a LiveDNS domain in
+// sync with the public addresses of the host, for machines whose
+// addresses change over time.
+package ddns
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"math/rand"
+	"net"
+	"net/http"
+	"time"
+
+	"github.com/go-gandi/go-gandi/livedns"
+	"github.com/go-gandi/go-gandi/types"
+)
+
+// DefaultTTL is the TTL of the updated records, short so that address
+// changes propagate quickly
+const DefaultTTL = 300
+
+// Family is an IP address family
+type Family int
+
+const (
+	// IPv4 addresses are published in A records
+	IPv4 Family = 4
+	// IPv6 addresses are published in AAAA records
+	IPv6 Family = 6
+)
+
+// RecordType returns the record type holding the addresses of the
+// family
+func (f Family) RecordType() string {
+	if f == IPv6 {
+		return "AAAA"
+	}
+	return "A"
+}
+
+func (f Family) String() string {
+	if f == IPv6 {
+		return "IPv6"
+	}
+	return "IPv4"
+}
+
+func (f Family) matches(ip net.IP) bool {
+	return ip != nil && (ip.To4() != nil) == (f == IPv4)
+}
+
+// Result describes the outcome of the update of a record
+type Result struct {
+	Type     string
+	Address  net.IP
+	Previous []string
+	Updated  bool
+}
+
+// Updater updates the A and AAAA records of a name with the current
+// addresses returned by its Source
+type Updater struct {
+	Client *livedns.LiveDNS
+	// Domain is the LiveDNS domain, such as "example.com"
+	Domain string
+	// Name is the record name relative to the domain, "@" for the
+	// apex
+	Name string
+	// Families are the address families to update
+	Families []Family
+	// Source returns the current addresses. DefaultSource is used if
+	// it is nil.
+	Source Source
+	// TTL of the records, DefaultTTL if zero
+	TTL int
+	// Interval between two updates when running in a loop
+	Interval time.Duration
+	// Jitter is the maximum random delay added to the interval, to
+	// spread the updates of many hosts
+	Jitter time.Duration
+	// Logger receives a line per update when not nil
+	Logger *log.Logger
+}
+
+// Update updates the records once. A record is only written when its
+// value or its TTL differ from the current address, and it is created
+// if it does not exist yet.
+func (u *Updater) Update(ctx context.Context) ([]Result, error) {
+	source := u.Source
+	if source == nil {
+		source = DefaultSource
+	}
+	ttl := u.TTL
+	if ttl == 0 {
+		ttl = DefaultTTL
+	}
+	var results []Result
+	for _, family := range u.Families {
+		ip, err := source.Lookup(ctx, family)
+		if err != nil {
+			return results, fmt.Errorf("Fail to get the current %s address (error '%w')", family, err)
+		}
+		if !family.matches(ip) {
+			return results, fmt.Errorf("The source returned '%s' which is not an %s address", ip, family)
+		}
+		result, err := u.updateRecord(family.RecordType(), ip, ttl)
+		if err != nil {
+			return results, err
+		}
+		u.logf("%s %s.%s: %s (updated: %t)", result.Type, u.Name, u.Domain, ip, result.Updated)
+		results = append(results, result)
+	}
+	return results, nil
+}
+
+func (u *Updater) updateRecord(rrtype string, ip net.IP, ttl int) (Result, error) {
+	result := Result{Type: rrtype, Address: ip}
+	value := ip.String()
+	current, err := u.Client.GetDomainRecordByNameAndType(u.Domain, u.Name, rrtype)
+	var e *types.RequestError
+	switch {
+	case errors.As(err, &e) && e.StatusCode == http.StatusNotFound:
+		_, err = u.Client.CreateDomainRecord(u.Domain, u.Name, rrtype, ttl, []string{value})
+	case err != nil:
+		return result, err
+	default:
+		result.Previous = current.RrsetValues
+		if current.RrsetTTL == ttl && len(current.RrsetValues) == 1 && net.ParseIP(current.RrsetValues[0]).Equal(ip) {
+			return result, nil
+		}
+		_, err = u.Client.UpdateDomainRecordByNameAndType(u.Domain, u.Name, rrtype, ttl, []string{value})
+	}
+	if err != nil {
+		return result, fmt.Errorf("Fail to update the %s record (error '%w')", rrtype, err)
+	}
+	result.Updated = true
+	return result, nil
+}
+
+// Run updates the records every Interval until the context is
+// canceled. Errors are logged and do not stop the loop, since the
+// next update may succeed. It returns the error of the context.
+func (u *Updater) Run(ctx context.Context) error {
+	if u.Interval <= 0 {
+		return fmt.Errorf("The update interval must be positive")
+	}
+	for {
+		if _, err := u.Update(ctx); err != nil {
+			u.logf("Update failed: %s", err)
+		}
+		delay := u.Interval
+		if u.Jitter > 0 {
+			delay += time.Duration(rand.Int63n(int64(u.Jitter)))
+		}
+		timer := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		case <-timer.C:
+		}
+	}
+}
+
+func (u *Updater) logf(format string, args ...interface{}) {
+	if u.Logger != nil {
+		u.Logger.Printf(format, args...)
+	}
+}
@@ -0,0 +1,52 @@
+package ddns_test
+
+import (
+	"context"
+	"net"
+	"testing"
+
+	"github.com/go-gandi/go-gandi/config"
+	"github.com/go-gandi/go-gandi/livedns"
+	"github.com/go-gandi/go-gandi/livedns/ddns"
+	"gopkg.in/h2non/gock.v1"
+)
+
+func TestUpdateOnlyWhenChanged(t *testing.T) {
+	defer gock.Off()
+	gock.New("https://api.gandi.net/v5/").
+		Get("livedns/domains/example.com/records/home/A").
+		Reply(200).
+		JSON(livedns.DomainRecord{RrsetTTL: 300, RrsetValues: []string{"192.0.2.1"}})
+	gock.New("https://api.gandi.net/v5/").
+		Get("livedns/domains/example.com/records/home/AAAA").
+		Reply(200).
+		JSON(livedns.DomainRecord{RrsetTTL: 300, RrsetValues: []string{"2001:db8::1"}})
+	gock.New("https://api.gandi.net/v5/").
+		Put("livedns/domains/example.com/records/home/AAAA").
+		JSON(livedns.DomainRecord{RrsetType: "AAAA", RrsetTTL: 300, RrsetValues: []string{"2001:db8::2"}}).
+		Reply(201).
+		JSON(map[string]string{"message": "DNS Record Created"})
+
+	updater := ddns.Updater{
+		Client:   livedns.New(config.Config{}),
+		Domain:   "example.com",
+		Name:     "home",
+		Families: []ddns.Family{ddns.IPv4, ddns.IPv6},
+		Source: ddns.SourceFunc(func(_ context.Context, family ddns.Family) (net.IP, error) {
+			if family == ddns.IPv4 {
+				return net.ParseIP("192.0.2.1"), nil
+			}
+			return net.ParseIP("2001:db8::2"), nil
+		}),
+	}
+	results, err := updater.Update(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(results) != 2 || results[0].Updated || !results[1].Updated {
+		t.Fatalf("Only the AAAA record should be updated (results are %+v)", results)
+	}
+	if !gock.IsDone() {
+		t.Fatal("All the expected requests have not been sent")
+	}
+}
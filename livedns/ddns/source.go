@@ -0,0 +1,110 @@
+package ddns
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// Source returns the current public address of the host for an
+// address family
+type Source interface {
+	Lookup(ctx context.Context, family Family) (net.IP, error)
+}
+
+// SourceFunc adapts a function to the Source interface
+type SourceFunc func(ctx context.Context, family Family) (net.IP, error)
+
+// Lookup calls f
+func (f SourceFunc) Lookup(ctx context.Context, family Family) (net.IP, error) {
+	return f(ctx, family)
+}
+
+// DefaultSource asks the ipify service for the address seen from the
+// Internet
+var DefaultSource Source = &HTTPSource{URL: "https://api64.ipify.org"}
+
+// HTTPSource gets the address from a web service answering with the
+// address of the client as plain text. The connection is forced to
+// use the requested family, so a single dual-stack URL serves both.
+type HTTPSource struct {
+	URL     string
+	Timeout time.Duration
+}
+
+// Lookup queries the web service over the address family
+func (s *HTTPSource) Lookup(ctx context.Context, family Family) (net.IP, error) {
+	network := "tcp4"
+	if family == IPv6 {
+		network = "tcp6"
+	}
+	dialer := &net.Dialer{}
+	timeout := s.Timeout
+	if timeout == 0 {
+		timeout = 10 * time.Second
+	}
+	client := &http.Client{
+		Timeout: timeout,
+		Transport: &http.Transport{
+			Proxy: http.ProxyFromEnvironment,
+			DialContext: func(ctx context.Context, _, addr string) (net.Conn, error) {
+				return dialer.DialContext(ctx, network, addr)
+			},
+		},
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, s.URL, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("%s returned the status %d", s.URL, resp.StatusCode)
+	}
+	body, err := io.ReadAll(io.LimitReader(resp.Body, 256))
+	if err != nil {
+		return nil, err
+	}
+	ip := net.ParseIP(strings.TrimSpace(string(body)))
+	if ip == nil {
+		return nil, fmt.Errorf("%s did not return an IP address", s.URL)
+	}
+	return ip, nil
+}
+
+// InterfaceSource gets the address from a local network interface,
+// for hosts which have their public address configured locally. The
+// first global unicast address of the family is used.
+type InterfaceSource struct {
+	Name string
+}
+
+// Lookup returns the first global unicast address of the interface
+func (s *InterfaceSource) Lookup(_ context.Context, family Family) (net.IP, error) {
+	iface, err := net.InterfaceByName(s.Name)
+	if err != nil {
+		return nil, err
+	}
+	addrs, err := iface.Addrs()
+	if err != nil {
+		return nil, err
+	}
+	for _, addr := range addrs {
+		ipnet, ok := addr.(*net.IPNet)
+		if !ok || !ipnet.IP.IsGlobalUnicast() || !family.matches(ipnet.IP) {
+			continue
+		}
+		if ipnet.IP.IsPrivate() {
+			continue
+		}
+		return ipnet.IP, nil
+	}
+	return nil, fmt.Errorf("No public %s address on the interface '%s'", family, s.Name)
+}
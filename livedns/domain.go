@@ -2,6 +2,7 @@ package livedns
 
 import (
 	"encoding/json"
+	"net/url"
 
 	"github.com/go-gandi/go-gandi/types"
 )
@@ -29,6 +30,33 @@ func (g *LiveDNS) CreateDomain(fqdn string, ttl int) (response types.StandardRes
 	return
 }
 
+// CreateDomainWithRecords adds a domain to a zone, with an initial set
+// of records
+func (g *LiveDNS) CreateDomainWithRecords(fqdn string, ttl int, records []DomainRecord) (response types.StandardResponse, err error) {
+	_, err = g.client.Post("domains", createDomainRequest{FQDN: fqdn, Zone: zone{TTL: ttl, Items: records}}, &response)
+	return
+}
+
+// CloneDomain adds a domain to a zone, with a copy of the records of
+// the source domain. Records of the source apex are copied to the apex
+// of the new domain.
+func (g *LiveDNS) CloneDomain(fqdn, source string, ttl int) (response types.StandardResponse, err error) {
+	records, err := g.GetDomainRecords(source)
+	if err != nil {
+		return
+	}
+	for i := range records {
+		records[i].RrsetHref = ""
+	}
+	return g.CreateDomainWithRecords(fqdn, ttl, records)
+}
+
+// DeleteDomain removes a domain from LiveDNS, along with its records
+func (g *LiveDNS) DeleteDomain(fqdn string) (err error) {
+	_, err = g.client.Delete("domains/"+fqdn, nil, nil)
+	return
+}
+
 // GetDomain returns a domain
 func (g *LiveDNS) GetDomain(fqdn string) (domain Domain, err error) {
 	_, err = g.client.Get("domains/"+fqdn, nil, &domain)
@@ -46,3 +74,16 @@ func (g *LiveDNS) GetDomainNS(fqdn string) (ns []string, err error) {
 	_, err = g.client.Get("domains/"+fqdn+"/nameservers", nil, &ns)
 	return
 }
+
+// GetDNSSECAvailability returns whether DNSSEC can be enabled on a
+// domain with LiveDNS, which depends on its TLD and registrar
+func (g *LiveDNS) GetDNSSECAvailability(fqdn string) (availability DNSSECAvailability, err error) {
+	_, err = g.client.Get("dns/dnssec-available?fqdn="+url.QueryEscape(fqdn), nil, &availability)
+	return
+}
+
+// ListRecordTypes returns the record types supported by LiveDNS
+func (g *LiveDNS) ListRecordTypes() (rrtypes []string, err error) {
+	_, err = g.client.Get("dns/rrtypes", nil, &rrtypes)
+	return
+}
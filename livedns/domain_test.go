@@ -0,0 +1,106 @@
+package livedns_test
+
+import (
+	"testing"
+
+	"github.com/go-gandi/go-gandi/config"
+	"github.com/go-gandi/go-gandi/livedns"
+	"gopkg.in/h2non/gock.v1"
+)
+
+func TestCreateDomain(t *testing.T) {
+	defer gock.Off()
+	gock.New("https://api.gandi.net/v5/").
+		Post("livedns/domains").
+		JSON(map[string]interface{}{"fqdn": "example.com", "zone": map[string]int{"ttl": 0}}).
+		Reply(201).
+		JSON(map[string]string{"message": "Domain Created"})
+
+	if _, err := livedns.New(config.Config{}).CreateDomain("example.com", 0); err != nil {
+		t.Fatal(err)
+	}
+	if !gock.IsDone() {
+		t.Fatal("All the expected requests have not been sent")
+	}
+}
+
+func TestCloneDomain(t *testing.T) {
+	defer gock.Off()
+	gock.New("https://api.gandi.net/v5/").
+		Get("livedns/domains/example.org/records").
+		Reply(200).
+		JSON([]livedns.DomainRecord{
+			{RrsetName: "@", RrsetType: "MX", RrsetTTL: 300, RrsetValues: []string{"10 spool.mail.gandi.net."}, RrsetHref: "https://api.gandi.net/v5/livedns/domains/example.org/records/%40/MX"},
+		})
+	// CreateDomainWithRecords sends the records without their href
+	gock.New("https://api.gandi.net/v5/").
+		Post("livedns/domains").
+		JSON(map[string]interface{}{"fqdn": "example.com", "zone": map[string]interface{}{
+			"ttl": 600,
+			"items": []livedns.DomainRecord{
+				{RrsetName: "@", RrsetType: "MX", RrsetTTL: 300, RrsetValues: []string{"10 spool.mail.gandi.net."}},
+			},
+		}}).
+		Reply(201).
+		JSON(map[string]string{"message": "Domain Created"})
+
+	if _, err := livedns.New(config.Config{}).CloneDomain("example.com", "example.org", 600); err != nil {
+		t.Fatal(err)
+	}
+	if !gock.IsDone() {
+		t.Fatal("All the expected requests have not been sent")
+	}
+}
+
+func TestDeleteDomain(t *testing.T) {
+	defer gock.Off()
+	gock.New("https://api.gandi.net/v5/").
+		Delete("livedns/domains/example.com").
+		Reply(204)
+
+	if err := livedns.New(config.Config{}).DeleteDomain("example.com"); err != nil {
+		t.Fatal(err)
+	}
+	if !gock.IsDone() {
+		t.Fatal("All the expected requests have not been sent")
+	}
+}
+
+func TestGetDNSSECAvailability(t *testing.T) {
+	defer gock.Off()
+	gock.New("https://api.gandi.net/v5/").
+		Get("livedns/dns/dnssec-available").
+		MatchParam("fqdn", "example.com").
+		Reply(200).
+		JSON(livedns.DNSSECAvailability{Available: true})
+
+	availability, err := livedns.New(config.Config{}).GetDNSSECAvailability("example.com")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !availability.Available {
+		t.Fatal("DNSSEC should be available")
+	}
+	if !gock.IsDone() {
+		t.Fatal("All the expected requests have not been sent")
+	}
+}
+
+func TestListRecordTypes(t *testing.T) {
+	defer gock.Off()
+	gock.New("https://api.gandi.net/v5/").
+		Get("livedns/dns/rrtypes").
+		Reply(200).
+		JSON([]string{"A", "AAAA", "MX"})
+
+	rrtypes, err := livedns.New(config.Config{}).ListRecordTypes()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(rrtypes) != 3 || rrtypes[2] != "MX" {
+		t.Fatalf("Unexpected record types %v", rrtypes)
+	}
+	if !gock.IsDone() {
+		t.Fatal("All the expected requests have not been sent")
+	}
+}
@@ -2,7 +2,9 @@ package livedns
 
 import (
 	"encoding/json"
+	"fmt"
 
+	"github.com/go-gandi/go-gandi/livedns/rdata"
 	"github.com/go-gandi/go-gandi/types"
 )
 
@@ -30,6 +32,23 @@ func (g *LiveDNS) GetDomainRecordsAsText(uuid string) ([]byte, error) {
 	return content, err
 }
 
+// ImportZoneText imports a zone file in the zone attached to a
+// domain. With ImportReplace, the records of the zone are replaced by
+// the ones of the zone file while ImportMerge adds them to the
+// existing records. When the client is configured in dry run mode, the
+// zone file is only validated by the API.
+func (g *LiveDNS) ImportZoneText(fqdn string, text []byte, mode ImportMode) (response types.StandardResponse, err error) {
+	switch mode {
+	case ImportReplace:
+		_, err = g.client.PutText("domains/"+fqdn+"/records", text, &response)
+	case ImportMerge:
+		_, err = g.client.PostText("domains/"+fqdn+"/records", text, &response)
+	default:
+		err = fmt.Errorf("Unknown import mode %d", mode)
+	}
+	return
+}
+
 // GetDomainRecordsByName lists all records with a specific name in a zone
 func (g *LiveDNS) GetDomainRecordsByName(fqdn, name string) (records []DomainRecord, err error) {
 	_, err = g.client.Get("domains/"+fqdn+"/records/"+name, nil, &records)
@@ -55,6 +74,19 @@ func (g *LiveDNS) CreateDomainRecord(fqdn, name, recordtype string, ttl int, val
 	return
 }
 
+// ValidateRecords checks the values of records with rdata.Validate.
+// The record methods do not validate the values themselves, so call it
+// before sending records to get a clear error instead of a rejection
+// from the API.
+func ValidateRecords(records []DomainRecord) error {
+	for _, record := range records {
+		if err := rdata.Validate(record.RrsetType, record.RrsetValues); err != nil {
+			return fmt.Errorf("Record '%s %s': %w", record.RrsetName, record.RrsetType, err)
+		}
+	}
+	return nil
+}
+
 type itemsPrefixForZoneRecords struct {
 	Items []DomainRecord `json:"items"`
 }
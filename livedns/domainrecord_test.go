@@ -0,0 +1,72 @@
+package livedns_test
+
+import (
+	"testing"
+
+	"github.com/go-gandi/go-gandi/config"
+	"github.com/go-gandi/go-gandi/livedns"
+	"gopkg.in/h2non/gock.v1"
+)
+
+func TestValidateRecords(t *testing.T) {
+	valid := []livedns.DomainRecord{
+		{RrsetName: "@", RrsetType: "MX", RrsetValues: []string{"10 spool.mail.gandi.net."}},
+		{RrsetName: "www", RrsetType: "UNKNOWN", RrsetValues: []string{"anything"}},
+	}
+	if err := livedns.ValidateRecords(valid); err != nil {
+		t.Fatal(err)
+	}
+	invalid := append(valid, livedns.DomainRecord{RrsetName: "www", RrsetType: "A", RrsetValues: []string{"2001:db8::1"}})
+	if err := livedns.ValidateRecords(invalid); err == nil {
+		t.Fatal("The A record should be rejected")
+	}
+}
+
+func TestCreateDomainRecordDoesNotValidate(t *testing.T) {
+	defer gock.Off()
+	// The values are left to the API to check
+	gock.New("https://api.gandi.net/v5/").
+		Post("livedns/domains/example.com/records").
+		JSON(livedns.DomainRecord{RrsetName: "www", RrsetType: "A", RrsetTTL: 300, RrsetValues: []string{"2001:db8::1"}}).
+		Reply(400).
+		JSON(map[string]interface{}{"code": 400, "message": "Invalid value"})
+
+	_, err := livedns.New(config.Config{}).CreateDomainRecord("example.com", "www", "A", 300, []string{"2001:db8::1"})
+	if err == nil {
+		t.Fatal("The API error should be returned")
+	}
+	if !gock.IsDone() {
+		t.Fatal("All the expected requests have not been sent")
+	}
+}
+
+func TestImportZoneText(t *testing.T) {
+	defer gock.Off()
+	zone := "www 300 IN A 192.0.2.1\n"
+	gock.New("https://api.gandi.net/v5/").
+		Put("livedns/domains/example.com/records").
+		MatchHeader("Content-Type", "text/plain").
+		BodyString(zone).
+		Reply(201).
+		JSON(map[string]string{"message": "Zone imported"})
+	gock.New("https://api.gandi.net/v5/").
+		Post("livedns/domains/example.com/records").
+		MatchHeader("Content-Type", "text/plain").
+		BodyString(zone).
+		Reply(201).
+		JSON(map[string]string{"message": "Zone merged"})
+
+	liveDNS := livedns.New(config.Config{})
+	if _, err := liveDNS.ImportZoneText("example.com", []byte(zone), livedns.ImportReplace); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := liveDNS.ImportZoneText("example.com", []byte(zone), livedns.ImportMerge); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := liveDNS.ImportZoneText("example.com", []byte(zone), livedns.ImportMode(42)); err == nil {
+		t.Fatal("An unknown import mode should be rejected")
+	}
+	if !gock.IsDone() {
+		t.Fatal("All the expected requests have not been sent")
+	}
+}
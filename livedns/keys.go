@@ -7,42 +7,6 @@ import (
 	"github.com/go-gandi/go-gandi/types"
 )
 
-// GetTSIGKeys retrieves all the TSIG keys for the account
-func (g *LiveDNS) GetTSIGKeys() (response []TSIGKey, err error) {
-	_, err = g.client.Get("axfr/tsig", nil, &response)
-	return
-}
-
-// GetTSIGKey retrieves the specified TSIG key
-func (g *LiveDNS) GetTSIGKey(id string) (response TSIGKey, err error) {
-	_, err = g.client.Get("axfr/tsig/"+id, nil, &response)
-	return
-}
-
-// CreateTSIGKey creates a TSIG key
-func (g *LiveDNS) CreateTSIGKey(fqdn string) (response TSIGKey, err error) {
-	_, err = g.client.Post("axfr/tsig", nil, &response)
-	return
-}
-
-// GetDomainTSIGKeys retrieves the specified TSIG key
-func (g *LiveDNS) GetDomainTSIGKeys(fqdn string) (response []TSIGKey, err error) {
-	_, err = g.client.Get("domains/"+fqdn+"/axfr/tsig", nil, &response)
-	return
-}
-
-// AssociateTSIGKeyWithDomain retrieves the specified TSIG key
-func (g *LiveDNS) AssociateTSIGKeyWithDomain(fqdn string, id string) (response types.StandardResponse, err error) {
-	_, err = g.client.Put("domains/"+fqdn+"/axfr/tsig/"+id, nil, &response)
-	return
-}
-
-// RemoveTSIGKeyFromDomain retrieves the specified TSIG key
-func (g *LiveDNS) RemoveTSIGKeyFromDomain(fqdn string, id string) (err error) {
-	_, err = g.client.Delete("domains/"+fqdn+"/axfr/tsig/"+id, nil, nil)
-	return
-}
-
 // SignDomain creates a DNSKEY and asks Gandi servers to automatically
 // sign the domain. The UUID of the created key is stored into the
 // response.UUID field.
@@ -36,3 +36,23 @@ func TestSignDomain(t *testing.T) {
 			expectedUUID, response.UUID)
 	}
 }
+
+func TestCreateAccountTSIGKey(t *testing.T) {
+	defer gock.Off()
+	gock.New("https://api.gandi.net/v5/").
+		Post("livedns/axfr/tsig").
+		Reply(201).
+		JSON(livedns.TSIGKey{ID: "key-id", KeyName: "gandi-key", Secret: "c2VjcmV0"})
+
+	// The key is only created, its association is a separate call
+	key, err := livedns.New(config.Config{}).CreateAccountTSIGKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if key.ID != "key-id" {
+		t.Fatalf("Unexpected key %+v", key)
+	}
+	if !gock.IsDone() {
+		t.Fatal("All the expected requests have not been sent")
+	}
+}
@@ -0,0 +1,256 @@
+package livedns
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// ChangeType is the kind of modification applied to a rrset
+type ChangeType string
+
+const (
+	// ChangeCreate creates a rrset which does not exist yet
+	ChangeCreate ChangeType = "create"
+	// ChangeUpdate replaces the values of a rrset (and possibly its TTL)
+	ChangeUpdate ChangeType = "update"
+	// ChangeTTL only changes the TTL of a rrset
+	ChangeTTL ChangeType = "ttl"
+	// ChangeDelete deletes a rrset
+	ChangeDelete ChangeType = "delete"
+)
+
+// Change is a modification of a single rrset, identified by its name
+// and type. Current is nil for a creation and Desired is nil for a
+// deletion.
+type Change struct {
+	Type      ChangeType
+	Name      string
+	RrsetType string
+	Current   *DomainRecord
+	Desired   *DomainRecord
+}
+
+func (c Change) String() string {
+	switch c.Type {
+	case ChangeCreate:
+		return fmt.Sprintf("+ %s %s %d %s", c.Name, c.RrsetType, c.Desired.RrsetTTL, formatValues(c.Desired.RrsetValues))
+	case ChangeDelete:
+		return fmt.Sprintf("- %s %s %d %s", c.Name, c.RrsetType, c.Current.RrsetTTL, formatValues(c.Current.RrsetValues))
+	case ChangeTTL:
+		return fmt.Sprintf("~ %s %s ttl %d -> %d", c.Name, c.RrsetType, c.Current.RrsetTTL, c.Desired.RrsetTTL)
+	default:
+		return fmt.Sprintf("~ %s %s %d %s -> %d %s", c.Name, c.RrsetType,
+			c.Current.RrsetTTL, formatValues(c.Current.RrsetValues),
+			c.Desired.RrsetTTL, formatValues(c.Desired.RrsetValues))
+	}
+}
+
+func formatValues(values []string) string {
+	return "[" + strings.Join(values, ", ") + "]"
+}
+
+// Plan is the list of changes needed to turn the zone attached to a
+// domain into a desired set of records
+type Plan struct {
+	FQDN    string
+	Changes []Change
+	// Protected holds the deletions which have been discarded
+	// because they would have broken the zone, such as the
+	// deletion of the apex NS records.
+	Protected []Change
+}
+
+// Empty returns true if the plan has no change to apply
+func (p Plan) Empty() bool {
+	return len(p.Changes) == 0
+}
+
+// Count returns the number of changes of the given type
+func (p Plan) Count(t ChangeType) (n int) {
+	for _, change := range p.Changes {
+		if change.Type == t {
+			n++
+		}
+	}
+	return
+}
+
+// String returns a human readable description of the plan: one line
+// per change, prefixed by "+" for creations, "~" for updates and "-"
+// for deletions.
+func (p Plan) String() string {
+	var b strings.Builder
+	for _, change := range p.Changes {
+		b.WriteString(change.String())
+		b.WriteString("\n")
+	}
+	for _, change := range p.Protected {
+		b.WriteString("! protected, not deleted: ")
+		b.WriteString(strings.TrimPrefix(change.String(), "- "))
+		b.WriteString("\n")
+	}
+	fmt.Fprintf(&b, "%d to create, %d to update, %d to delete\n",
+		p.Count(ChangeCreate), p.Count(ChangeUpdate)+p.Count(ChangeTTL), p.Count(ChangeDelete))
+	return b.String()
+}
+
+// PlanOptions contains the params for the PlanZone method
+type PlanOptions struct {
+	// MaxDeletions makes PlanZone fail if the plan deletes more
+	// rrsets than this limit. Zero means no limit.
+	MaxDeletions int
+	// KeepUnmanaged prevents the deletion of the rrsets which are
+	// not part of the desired records.
+	KeepUnmanaged bool
+	// AllowApexNSDeletion allows the deletion of the NS and SOA
+	// records of the zone apex, which are protected by default.
+	AllowApexNSDeletion bool
+}
+
+// ErrTooManyDeletions is returned by PlanZone when the plan deletes
+// more rrsets than allowed by PlanOptions.MaxDeletions
+var ErrTooManyDeletions = errors.New("Too many deletions")
+
+type rrsetKey struct {
+	name  string
+	rtype string
+}
+
+func keyOf(record DomainRecord) rrsetKey {
+	return rrsetKey{name: record.RrsetName, rtype: strings.ToUpper(record.RrsetType)}
+}
+
+// DiffRecords computes the changes turning the current records into
+// the desired ones. Values are compared regardless of their order.
+// Changes are sorted by name and type.
+func DiffRecords(current, desired []DomainRecord) []Change {
+	existing := map[rrsetKey]DomainRecord{}
+	for _, record := range current {
+		existing[keyOf(record)] = record
+	}
+	wanted := map[rrsetKey]bool{}
+	var changes []Change
+	for i := range desired {
+		d := desired[i]
+		key := keyOf(d)
+		wanted[key] = true
+		c, ok := existing[key]
+		switch {
+		case !ok:
+			changes = append(changes, Change{Type: ChangeCreate, Name: key.name, RrsetType: key.rtype, Desired: &d})
+		case !sameValues(c.RrsetValues, d.RrsetValues):
+			changes = append(changes, Change{Type: ChangeUpdate, Name: key.name, RrsetType: key.rtype, Current: &c, Desired: &d})
+		case c.RrsetTTL != d.RrsetTTL:
+			changes = append(changes, Change{Type: ChangeTTL, Name: key.name, RrsetType: key.rtype, Current: &c, Desired: &d})
+		}
+	}
+	for i := range current {
+		c := current[i]
+		key := keyOf(c)
+		if !wanted[key] {
+			changes = append(changes, Change{Type: ChangeDelete, Name: key.name, RrsetType: key.rtype, Current: &c})
+		}
+	}
+	sort.SliceStable(changes, func(i, j int) bool {
+		if changes[i].Name != changes[j].Name {
+			return changes[i].Name < changes[j].Name
+		}
+		return changes[i].RrsetType < changes[j].RrsetType
+	})
+	return changes
+}
+
+func sameValues(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	a = append([]string(nil), a...)
+	b = append([]string(nil), b...)
+	sort.Strings(a)
+	sort.Strings(b)
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func withoutApexSOA(records []DomainRecord) []DomainRecord {
+	var kept []DomainRecord
+	for _, record := range records {
+		if record.RrsetName == "@" && strings.EqualFold(record.RrsetType, "SOA") {
+			continue
+		}
+		kept = append(kept, record)
+	}
+	return kept
+}
+
+func isProtected(change Change) bool {
+	return change.Name == "@" && (change.RrsetType == "NS" || change.RrsetType == "SOA")
+}
+
+// PlanZone fetches the records of the zone attached to a domain and
+// computes the changes needed to get the desired records. The apex
+// SOA of the desired records, as found in most zone files, is
+// ignored: LiveDNS manages it and rejects its creation.
+func (g *LiveDNS) PlanZone(fqdn string, desired []DomainRecord, opts PlanOptions) (plan Plan, err error) {
+	current, err := g.GetDomainRecords(fqdn)
+	if err != nil {
+		return
+	}
+	plan.FQDN = fqdn
+	deletions := 0
+	for _, change := range DiffRecords(current, withoutApexSOA(desired)) {
+		if change.Type == ChangeDelete {
+			if opts.KeepUnmanaged {
+				continue
+			}
+			if !opts.AllowApexNSDeletion && isProtected(change) {
+				plan.Protected = append(plan.Protected, change)
+				continue
+			}
+			deletions++
+		}
+		plan.Changes = append(plan.Changes, change)
+	}
+	if opts.MaxDeletions > 0 && deletions > opts.MaxDeletions {
+		err = fmt.Errorf("%w: the plan deletes %d rrsets (limit is %d)", ErrTooManyDeletions, deletions, opts.MaxDeletions)
+	}
+	return
+}
+
+// ApplyPlan applies the changes of a plan with one API call per
+// rrset. Deletions are applied first, then updates and creations, so
+// that a name can switch to a CNAME record. It stops at the first
+// error.
+func (g *LiveDNS) ApplyPlan(plan Plan) error {
+	for _, t := range []ChangeType{ChangeDelete, ChangeUpdate, ChangeTTL, ChangeCreate} {
+		for _, change := range plan.Changes {
+			if change.Type != t {
+				continue
+			}
+			if err := g.applyChange(plan.FQDN, change); err != nil {
+				return fmt.Errorf("Fail to apply '%s' (error '%w')", change, err)
+			}
+		}
+	}
+	return nil
+}
+
+func (g *LiveDNS) applyChange(fqdn string, change Change) (err error) {
+	switch change.Type {
+	case ChangeCreate:
+		d := change.Desired
+		_, err = g.CreateDomainRecord(fqdn, d.RrsetName, d.RrsetType, d.RrsetTTL, d.RrsetValues)
+	case ChangeUpdate, ChangeTTL:
+		d := change.Desired
+		_, err = g.UpdateDomainRecordByNameAndType(fqdn, d.RrsetName, d.RrsetType, d.RrsetTTL, d.RrsetValues)
+	case ChangeDelete:
+		err = g.DeleteDomainRecord(fqdn, change.Current.RrsetName, change.Current.RrsetType)
+	}
+	return
+}
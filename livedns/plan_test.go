@@ -0,0 +1,92 @@
+package livedns_test
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/go-gandi/go-gandi/config"
+	"github.com/go-gandi/go-gandi/livedns"
+	"github.com/go-gandi/go-gandi/livedns/zonefile"
+	"gopkg.in/h2non/gock.v1"
+)
+
+func TestDiffRecords(t *testing.T) {
+	current := []livedns.DomainRecord{
+		{RrsetName: "www", RrsetType: "A", RrsetTTL: 300, RrsetValues: []string{"192.0.2.1", "192.0.2.2"}},
+		{RrsetName: "ttl", RrsetType: "A", RrsetTTL: 300, RrsetValues: []string{"192.0.2.1"}},
+		{RrsetName: "old", RrsetType: "CNAME", RrsetTTL: 300, RrsetValues: []string{"www"}},
+	}
+	desired := []livedns.DomainRecord{
+		{RrsetName: "www", RrsetType: "a", RrsetTTL: 300, RrsetValues: []string{"192.0.2.2", "192.0.2.1"}},
+		{RrsetName: "ttl", RrsetType: "A", RrsetTTL: 600, RrsetValues: []string{"192.0.2.1"}},
+		{RrsetName: "new", RrsetType: "A", RrsetTTL: 300, RrsetValues: []string{"192.0.2.3"}},
+	}
+	changes := livedns.DiffRecords(current, desired)
+	expected := []livedns.ChangeType{livedns.ChangeCreate, livedns.ChangeDelete, livedns.ChangeTTL}
+	if len(changes) != len(expected) {
+		t.Fatalf("There should be %d changes (while there are %v)", len(expected), changes)
+	}
+	for i, change := range changes {
+		if change.Type != expected[i] {
+			t.Fatalf("Change %d should be a %s (while it is %s)", i, expected[i], change)
+		}
+	}
+}
+
+func TestPlanZone(t *testing.T) {
+	defer gock.Off()
+	gock.New("https://api.gandi.net/v5/").
+		Get("livedns/domains/example.com/records").
+		Persist().
+		Reply(200).
+		JSON([]livedns.DomainRecord{
+			{RrsetName: "@", RrsetType: "NS", RrsetTTL: 10800, RrsetValues: []string{"ns1.gandi.net."}},
+			{RrsetName: "a", RrsetType: "A", RrsetTTL: 300, RrsetValues: []string{"192.0.2.1"}},
+			{RrsetName: "b", RrsetType: "A", RrsetTTL: 300, RrsetValues: []string{"192.0.2.1"}},
+		})
+
+	liveDNS := livedns.New(config.Config{})
+	plan, err := liveDNS.PlanZone("example.com", nil, livedns.PlanOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if plan.Count(livedns.ChangeDelete) != 2 || len(plan.Protected) != 1 {
+		t.Fatalf("The apex NS records should be protected:\n%s", plan)
+	}
+
+	_, err = liveDNS.PlanZone("example.com", nil, livedns.PlanOptions{MaxDeletions: 1})
+	if !errors.Is(err, livedns.ErrTooManyDeletions) {
+		t.Fatalf("Error should be ErrTooManyDeletions (while it is %v)", err)
+	}
+}
+
+func TestPlanZoneIgnoresSOA(t *testing.T) {
+	defer gock.Off()
+	gock.New("https://api.gandi.net/v5/").
+		Get("livedns/domains/example.com/records").
+		Reply(200).
+		JSON([]livedns.DomainRecord{
+			{RrsetName: "@", RrsetType: "NS", RrsetTTL: 10800, RrsetValues: []string{"ns1.gandi.net."}},
+			{RrsetName: "www", RrsetType: "A", RrsetTTL: 300, RrsetValues: []string{"192.0.2.1"}},
+		})
+
+	desired, err := zonefile.Parse(strings.NewReader(`$ORIGIN example.com.
+@ 10800 IN SOA ns1.gandi.net. hostmaster.gandi.net. 1 10800 3600 604800 10800
+@ 10800 IN NS ns1.gandi.net.
+www 300 IN A 192.0.2.2
+`), "example.com")
+	if err != nil {
+		t.Fatal(err)
+	}
+	plan, err := livedns.New(config.Config{}).PlanZone("example.com", desired, livedns.PlanOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(plan.Changes) != 1 || plan.Changes[0].Type != livedns.ChangeUpdate || plan.Changes[0].Name != "www" {
+		t.Fatalf("Only the www A record should be updated:\n%s", plan)
+	}
+	if !gock.IsDone() {
+		t.Fatal("All the expected requests have not been sent")
+	}
+}
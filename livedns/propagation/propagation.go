@@ -0,0 +1,283 @@
+// Package propagation checks that changes made with LiveDNS are
+// served by the authoritative nameservers of a domain.
+//
+// Each nameserver returned by LiveDNS for the domain is queried
+// directly, at each of its addresses, so that the answers do not come
+// from a cache.
+package propagation
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"strings"
+	"time"
+
+	"github.com/go-gandi/go-gandi/livedns"
+	"github.com/miekg/dns"
+)
+
+// ErrTimeout is returned by Wait when some nameservers still do not
+// serve the expected records at the end of the timeout
+var ErrTimeout = errors.New("The change has not propagated to all the nameservers")
+
+// Resolver resolves the addresses of the nameservers and queries them
+type Resolver interface {
+	// LookupHost returns the addresses of a nameserver
+	LookupHost(ctx context.Context, host string) ([]string, error)
+	// Exchange sends a query to a server, given as host:port
+	Exchange(ctx context.Context, m *dns.Msg, server string) (*dns.Msg, error)
+}
+
+// NetResolver resolves nameservers with the system resolver and
+// queries them over UDP, retrying over TCP for truncated answers
+type NetResolver struct {
+	// Network is "ip4" or "ip6" to only query the nameservers over
+	// one family, "ip" if empty
+	Network string
+	// Timeout of a query, 5 seconds if zero
+	Timeout time.Duration
+}
+
+// LookupHost returns the addresses of the host for the network
+func (r *NetResolver) LookupHost(ctx context.Context, host string) ([]string, error) {
+	network := r.Network
+	if network == "" {
+		network = "ip"
+	}
+	ips, err := net.DefaultResolver.LookupIP(ctx, network, host)
+	if err != nil {
+		return nil, err
+	}
+	addrs := make([]string, 0, len(ips))
+	for _, ip := range ips {
+		addrs = append(addrs, ip.String())
+	}
+	return addrs, nil
+}
+
+// Exchange sends the query to the server
+func (r *NetResolver) Exchange(ctx context.Context, m *dns.Msg, server string) (*dns.Msg, error) {
+	timeout := r.Timeout
+	if timeout == 0 {
+		timeout = 5 * time.Second
+	}
+	client := &dns.Client{Timeout: timeout}
+	resp, _, err := client.ExchangeContext(ctx, m, server)
+	if err == nil && resp.Truncated {
+		client.Net = "tcp"
+		resp, _, err = client.ExchangeContext(ctx, m, server)
+	}
+	return resp, err
+}
+
+// Status is the state of the rrset on one address of a nameserver
+type Status struct {
+	Nameserver string
+	Address    string
+	// Values and TTL are those served by the nameserver
+	Values []string
+	TTL    int
+	Synced bool
+	// Err is the error of the query, if any
+	Err error
+}
+
+func (s Status) String() string {
+	switch {
+	case s.Err != nil:
+		return fmt.Sprintf("%s (%s): %s", s.Nameserver, s.Address, s.Err)
+	case s.Synced:
+		return fmt.Sprintf("%s (%s): synced", s.Nameserver, s.Address)
+	}
+	return fmt.Sprintf("%s (%s): %d %v", s.Nameserver, s.Address, s.TTL, s.Values)
+}
+
+// Synced returns whether all the statuses are synced
+func Synced(statuses []Status) bool {
+	for _, s := range statuses {
+		if !s.Synced {
+			return false
+		}
+	}
+	return len(statuses) > 0
+}
+
+// Checker compares the records served by the nameservers of a domain
+// with the expected ones
+type Checker struct {
+	Client *livedns.LiveDNS
+	// Resolver used to query the nameservers, a NetResolver if nil
+	Resolver Resolver
+	// Port of the nameservers, "53" if empty
+	Port string
+	// Interval between two checks while waiting, 5 seconds if zero
+	Interval time.Duration
+}
+
+// Check queries every address of the nameservers of the domain once.
+// The expected record gives the name, the type, the values and the
+// TTL of the rrset; a zero TTL matches any TTL, and no values means
+// that the rrset must not exist.
+func (c *Checker) Check(ctx context.Context, fqdn string, expected livedns.DomainRecord) ([]Status, error) {
+	nameservers, err := c.Client.GetDomainNS(fqdn)
+	if err != nil {
+		return nil, fmt.Errorf("Fail to get the nameservers of '%s' (error '%w')", fqdn, err)
+	}
+	if len(nameservers) == 0 {
+		return nil, fmt.Errorf("The domain '%s' has no nameserver", fqdn)
+	}
+	zone := dns.Fqdn(fqdn)
+	want, err := parseValues(zone, expected)
+	if err != nil {
+		return nil, err
+	}
+	name := zone
+	if expected.RrsetName != "@" && expected.RrsetName != "" {
+		name = expected.RrsetName + "." + zone
+	}
+	qtype, ok := dns.StringToType[strings.ToUpper(expected.RrsetType)]
+	if !ok {
+		return nil, fmt.Errorf("Unknown record type '%s'", expected.RrsetType)
+	}
+
+	resolver := c.resolver()
+	port := c.Port
+	if port == "" {
+		port = "53"
+	}
+	var statuses []Status
+	for _, ns := range nameservers {
+		ns = strings.TrimSuffix(ns, ".")
+		addrs, err := resolver.LookupHost(ctx, ns)
+		if err != nil {
+			statuses = append(statuses, Status{Nameserver: ns, Err: err})
+			continue
+		}
+		for _, addr := range addrs {
+			status := Status{Nameserver: ns, Address: addr}
+			rrs, err := query(ctx, resolver, net.JoinHostPort(addr, port), name, qtype)
+			if err != nil {
+				status.Err = err
+			} else {
+				for _, rr := range rrs {
+					status.Values = append(status.Values, strings.TrimPrefix(rr.String(), rr.Header().String()))
+					status.TTL = int(rr.Header().Ttl)
+				}
+				status.Synced = matches(status.TTL, want, expected.RrsetTTL, rrs)
+			}
+			statuses = append(statuses, status)
+		}
+	}
+	return statuses, nil
+}
+
+func (c *Checker) resolver() Resolver {
+	if c.Resolver == nil {
+		return &NetResolver{}
+	}
+	return c.Resolver
+}
+
+// query returns the records of the rrset served by a nameserver
+func query(ctx context.Context, resolver Resolver, server, name string, qtype uint16) ([]dns.RR, error) {
+	m := new(dns.Msg)
+	m.SetQuestion(name, qtype)
+	m.RecursionDesired = false
+	resp, err := resolver.Exchange(ctx, m, server)
+	if err != nil {
+		return nil, err
+	}
+	if !resp.Authoritative {
+		return nil, fmt.Errorf("The answer is not authoritative")
+	}
+	if resp.Rcode != dns.RcodeSuccess && resp.Rcode != dns.RcodeNameError {
+		return nil, fmt.Errorf("The nameserver answered %s", dns.RcodeToString[resp.Rcode])
+	}
+	var rrs []dns.RR
+	for _, rr := range resp.Answer {
+		h := rr.Header()
+		if h.Rrtype == qtype && strings.EqualFold(h.Name, name) {
+			rrs = append(rrs, rr)
+		}
+	}
+	return rrs, nil
+}
+
+// Wait checks the nameservers every Interval until they all serve the
+// expected records, the timeout expires or the context is canceled.
+// The last statuses are returned with ErrTimeout when the change has
+// not propagated in time.
+func (c *Checker) Wait(ctx context.Context, fqdn string, expected livedns.DomainRecord, timeout time.Duration) ([]Status, error) {
+	interval := c.Interval
+	if interval == 0 {
+		interval = 5 * time.Second
+	}
+	deadline := time.Now().Add(timeout)
+	for {
+		statuses, err := c.Check(ctx, fqdn, expected)
+		if err != nil {
+			return statuses, err
+		}
+		if Synced(statuses) {
+			return statuses, nil
+		}
+		if time.Now().Add(interval).After(deadline) {
+			return statuses, ErrTimeout
+		}
+		timer := time.NewTimer(interval)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return statuses, ctx.Err()
+		case <-timer.C:
+		}
+	}
+}
+
+// parseValues returns the expected values as resource records, so
+// that they can be compared with the answers regardless of their
+// presentation
+func parseValues(zone string, expected livedns.DomainRecord) ([]dns.RR, error) {
+	owner := zone
+	if expected.RrsetName != "@" && expected.RrsetName != "" {
+		owner = expected.RrsetName + "." + zone
+	}
+	rrs := make([]dns.RR, 0, len(expected.RrsetValues))
+	for _, value := range expected.RrsetValues {
+		line := fmt.Sprintf("%s 0 IN %s %s", owner, expected.RrsetType, value)
+		parser := dns.NewZoneParser(strings.NewReader(line), zone, "")
+		rr, ok := parser.Next()
+		if !ok {
+			if err := parser.Err(); err != nil {
+				return nil, err
+			}
+			return nil, fmt.Errorf("Cannot parse the %s value '%s'", expected.RrsetType, value)
+		}
+		rrs = append(rrs, rr)
+	}
+	return rrs, nil
+}
+
+func matches(ttl int, want []dns.RR, wantTTL int, got []dns.RR) bool {
+	if len(want) != len(got) {
+		return false
+	}
+	if len(want) > 0 && wantTTL != 0 && ttl != wantTTL {
+		return false
+	}
+	for _, w := range want {
+		found := false
+		for _, g := range got {
+			if dns.IsDuplicate(w, g) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
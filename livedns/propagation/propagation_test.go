@@ -0,0 +1,137 @@
+package propagation_test
+
+import (
+	"context"
+	"net"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/go-gandi/go-gandi/config"
+	"github.com/go-gandi/go-gandi/livedns"
+	"github.com/go-gandi/go-gandi/livedns/propagation"
+	"github.com/miekg/dns"
+	"gopkg.in/h2non/gock.v1"
+)
+
+// zoneServer is an authoritative server answering from a map which
+// can be changed while it runs
+type zoneServer struct {
+	mu      sync.Mutex
+	records map[string][]dns.RR
+	addr    string
+}
+
+func (z *zoneServer) set(t *testing.T, key string, rrs ...string) {
+	var parsed []dns.RR
+	for _, s := range rrs {
+		rr, err := dns.NewRR(s)
+		if err != nil {
+			t.Fatal(err)
+		}
+		parsed = append(parsed, rr)
+	}
+	z.mu.Lock()
+	z.records[key] = parsed
+	z.mu.Unlock()
+}
+
+func (z *zoneServer) ServeDNS(w dns.ResponseWriter, r *dns.Msg) {
+	m := new(dns.Msg)
+	m.SetReply(r)
+	m.Authoritative = true
+	q := r.Question[0]
+	z.mu.Lock()
+	m.Answer = z.records[q.Name+" "+dns.TypeToString[q.Qtype]]
+	z.mu.Unlock()
+	_ = w.WriteMsg(m)
+}
+
+func startServer(t *testing.T) *zoneServer {
+	z := &zoneServer{records: map[string][]dns.RR{}}
+	pc, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	z.addr = pc.LocalAddr().String()
+	started := make(chan struct{})
+	server := &dns.Server{PacketConn: pc, Handler: z, NotifyStartedFunc: func() { close(started) }}
+	go func() { _ = server.ActivateAndServe() }()
+	<-started
+	t.Cleanup(func() { _ = server.Shutdown() })
+	return z
+}
+
+// testResolver gives a fake address to each nameserver and sends the
+// queries to the local server of this address
+type testResolver struct {
+	hosts   map[string]string
+	servers map[string]*zoneServer
+}
+
+func (r *testResolver) LookupHost(_ context.Context, host string) ([]string, error) {
+	return []string{r.hosts[host]}, nil
+}
+
+func (r *testResolver) Exchange(ctx context.Context, m *dns.Msg, server string) (*dns.Msg, error) {
+	host, _, _ := net.SplitHostPort(server)
+	resp, _, err := new(dns.Client).ExchangeContext(ctx, m, r.servers[host].addr)
+	return resp, err
+}
+
+func TestWait(t *testing.T) {
+	defer gock.Off()
+	gock.New("https://api.gandi.net/v5/").
+		Get("livedns/domains/example.com/nameservers").
+		Persist().
+		Reply(200).
+		JSON([]string{"ns1.example.net", "ns2.example.net."})
+
+	ns1, ns2 := startServer(t), startServer(t)
+	ns1.set(t, "www.example.com. A", "www.example.com. 300 IN A 192.0.2.10", "www.example.com. 300 IN A 192.0.2.11")
+	ns2.set(t, "www.example.com. A", "www.example.com. 300 IN A 192.0.2.10")
+
+	checker := propagation.Checker{
+		Client: livedns.New(config.Config{}),
+		Resolver: &testResolver{
+			hosts:   map[string]string{"ns1.example.net": "192.0.2.1", "ns2.example.net": "192.0.2.2"},
+			servers: map[string]*zoneServer{"192.0.2.1": ns1, "192.0.2.2": ns2},
+		},
+		Interval: 10 * time.Millisecond,
+	}
+	expected := livedns.DomainRecord{RrsetName: "www", RrsetType: "A", RrsetTTL: 300, RrsetValues: []string{"192.0.2.11", "192.0.2.10"}}
+
+	statuses, err := checker.Check(context.Background(), "example.com", expected)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(statuses) != 2 || !statuses[0].Synced || statuses[1].Synced {
+		t.Fatalf("Only ns1 should be synced (statuses are %v)", statuses)
+	}
+
+	statuses, err = checker.Wait(context.Background(), "example.com", expected, 30*time.Millisecond)
+	if err != propagation.ErrTimeout {
+		t.Fatalf("Expected a timeout, got %v (statuses are %v)", err, statuses)
+	}
+
+	go func() {
+		time.Sleep(20 * time.Millisecond)
+		ns2.set(t, "www.example.com. A", "www.example.com. 300 IN A 192.0.2.11", "www.example.com. 300 IN A 192.0.2.10")
+	}()
+	statuses, err = checker.Wait(context.Background(), "example.com", expected, time.Second)
+	if err != nil || !propagation.Synced(statuses) {
+		t.Fatalf("Expected all the nameservers to be synced, got %v (statuses are %v)", err, statuses)
+	}
+
+	expected.RrsetTTL = 600
+	statuses, _ = checker.Check(context.Background(), "example.com", expected)
+	if propagation.Synced(statuses) {
+		t.Fatalf("A different TTL should not be synced (statuses are %v)", statuses)
+	}
+
+	deleted := livedns.DomainRecord{RrsetName: "old", RrsetType: "A"}
+	statuses, _ = checker.Check(context.Background(), "example.com", deleted)
+	if !propagation.Synced(statuses) {
+		t.Fatalf("A missing rrset should be synced when no value is expected (statuses are %v)", statuses)
+	}
+}
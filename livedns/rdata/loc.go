@@ -0,0 +1,138 @@
+package rdata
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// LOC is a location record (RFC 1876). Latitude and longitude are in
+// degrees, negative in the southern and western hemispheres, the other
+// fields are in meters.
+type LOC struct {
+	Latitude       float64
+	Longitude      float64
+	Altitude       float64
+	Size           float64
+	HorizPrecision float64
+	VertPrecision  float64
+}
+
+// NewLOC returns a location with the default size and precisions of
+// RFC 1876
+func NewLOC(latitude, longitude, altitude float64) LOC {
+	return LOC{
+		Latitude:       latitude,
+		Longitude:      longitude,
+		Altitude:       altitude,
+		Size:           1,
+		HorizPrecision: 10000,
+		VertPrecision:  10,
+	}
+}
+
+// Type returns "LOC"
+func (v LOC) Type() string { return "LOC" }
+
+func (v LOC) String() string {
+	return fmt.Sprintf("%s %s %.2fm %.2fm %.2fm %.2fm",
+		formatCoordinate(v.Latitude, "N", "S"),
+		formatCoordinate(v.Longitude, "E", "W"),
+		v.Altitude, v.Size, v.HorizPrecision, v.VertPrecision)
+}
+
+func formatCoordinate(deg float64, positive, negative string) string {
+	hemisphere := positive
+	if deg < 0 {
+		hemisphere = negative
+		deg = -deg
+	}
+	// Work in thousandths of seconds to avoid rounding to 60 seconds
+	ms := int64(math.Round(deg * 3600000))
+	d := ms / 3600000
+	m := (ms / 60000) % 60
+	s := float64(ms%60000) / 1000
+	return fmt.Sprintf("%d %d %.3f %s", d, m, s, hemisphere)
+}
+
+// Validate checks the coordinates and sizes are in the ranges allowed
+// by RFC 1876
+func (v LOC) Validate() error {
+	if v.Latitude < -90 || v.Latitude > 90 {
+		return fmt.Errorf("the latitude must be between -90 and 90 degrees")
+	}
+	if v.Longitude < -180 || v.Longitude > 180 {
+		return fmt.Errorf("the longitude must be between -180 and 180 degrees")
+	}
+	if v.Altitude < -100000 || v.Altitude > 42849672.95 {
+		return fmt.Errorf("the altitude must be between -100000 and 42849672.95 meters")
+	}
+	for _, size := range []float64{v.Size, v.HorizPrecision, v.VertPrecision} {
+		if size < 0 || size > 90000000 {
+			return fmt.Errorf("sizes and precisions must be between 0 and 90000000 meters")
+		}
+	}
+	return nil
+}
+
+func parseLOC(fields []string, _ string) (Value, error) {
+	lat, fields, err := parseCoordinate(fields, "N", "S", 90)
+	if err != nil {
+		return nil, err
+	}
+	lon, fields, err := parseCoordinate(fields, "E", "W", 180)
+	if err != nil {
+		return nil, err
+	}
+	if len(fields) == 0 || len(fields) > 4 {
+		return nil, fmt.Errorf("expected an altitude and up to 3 sizes")
+	}
+	v := NewLOC(lat, lon, 0)
+	targets := []*float64{&v.Altitude, &v.Size, &v.HorizPrecision, &v.VertPrecision}
+	for i, field := range fields {
+		meters, err := strconv.ParseFloat(strings.TrimSuffix(field, "m"), 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid distance '%s'", field)
+		}
+		*targets[i] = meters
+	}
+	return v, nil
+}
+
+// parseCoordinate parses "d [m [s]] H" and returns the remaining
+// fields
+func parseCoordinate(fields []string, positive, negative string, max float64) (float64, []string, error) {
+	var parts []float64
+	for i, field := range fields {
+		if i > 3 {
+			break
+		}
+		h := strings.ToUpper(field)
+		if h == positive || h == negative {
+			if len(parts) == 0 {
+				break
+			}
+			deg := parts[0]
+			if len(parts) > 1 {
+				deg += parts[1] / 60
+			}
+			if len(parts) > 2 {
+				deg += parts[2] / 3600
+			}
+			if deg > max {
+				return 0, nil, fmt.Errorf("the coordinate %f is out of range", deg)
+			}
+			if h == negative {
+				deg = -deg
+			}
+			return deg, fields[i+1:], nil
+		}
+		n, err := strconv.ParseFloat(field, 64)
+		if err != nil {
+			return 0, nil, fmt.Errorf("invalid coordinate '%s'", field)
+		}
+		parts = append(parts, n)
+	}
+	return 0, nil, fmt.Errorf("expected a coordinate ending with %s or %s", positive, negative)
+}
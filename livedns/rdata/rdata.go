@@ -0,0 +1,199 @@
+// Package rdata provides typed builders, parsers and validation for
+// the values of LiveDNS records.
+//
+// LiveDNS stores the values of a rrset as strings in the DNS
+// presentation format. Each type of this package produces such a
+// string with its String method, and Parse reads it back, so values can
+// be checked before being sent to the API:
+//
+//	values := rdata.MustStrings(
+//		rdata.MX{Preference: 10, Host: "spool.mail.gandi.net."},
+//		rdata.MX{Preference: 50, Host: "fb.mail.gandi.net."},
+//	)
+package rdata
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Value is the typed value of a DNS record
+type Value interface {
+	// Type returns the record type, such as "MX"
+	Type() string
+	// String returns the value in the presentation format
+	String() string
+	// Validate checks the value can be stored in a zone
+	Validate() error
+}
+
+// Error describes an invalid record value
+type Error struct {
+	Type  string
+	Value string
+	Err   string
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("Invalid %s value '%s': %s", e.Type, e.Value, e.Err)
+}
+
+type parseFunc func(fields []string, raw string) (Value, error)
+
+var parsers = map[string]parseFunc{
+	"A":     parseA,
+	"AAAA":  parseAAAA,
+	"CNAME": hostParser(func(h string) Value { return CNAME{Target: h} }),
+	"NS":    hostParser(func(h string) Value { return NS{Host: h} }),
+	"PTR":   hostParser(func(h string) Value { return PTR{Host: h} }),
+	"ALIAS": hostParser(func(h string) Value { return ALIAS{Target: h} }),
+	"DNAME": hostParser(func(h string) Value { return DNAME{Target: h} }),
+	"MX":    parseMX,
+	"TXT":   func(_ []string, raw string) (Value, error) { return parseTXT(raw, false) },
+	"SPF":   func(_ []string, raw string) (Value, error) { return parseTXT(raw, true) },
+	"SRV":   parseSRV,
+	"CAA":   parseCAA,
+	"TLSA":  parseTLSA,
+	"SSHFP": parseSSHFP,
+	"DS":    parseDS,
+	"LOC":   parseLOC,
+	"SVCB":  svcbParser("SVCB"),
+	"HTTPS": svcbParser("HTTPS"),
+}
+
+// Supported returns true if the record type can be parsed by this
+// package
+func Supported(rrtype string) bool {
+	_, ok := parsers[strings.ToUpper(rrtype)]
+	return ok
+}
+
+// Parse parses a value in the presentation format and validates it.
+// An error is returned for the record types which are not supported.
+func Parse(rrtype, value string) (Value, error) {
+	rrtype = strings.ToUpper(rrtype)
+	parse, ok := parsers[rrtype]
+	if !ok {
+		return nil, fmt.Errorf("Unsupported record type '%s'", rrtype)
+	}
+	v, err := parse(strings.Fields(value), value)
+	if err != nil {
+		return nil, &Error{Type: rrtype, Value: value, Err: err.Error()}
+	}
+	if err := v.Validate(); err != nil {
+		return nil, &Error{Type: rrtype, Value: value, Err: err.Error()}
+	}
+	return v, nil
+}
+
+// Validate checks the values of a rrset. Values of unsupported record
+// types are not checked.
+func Validate(rrtype string, values []string) error {
+	if !Supported(rrtype) {
+		return nil
+	}
+	if strings.EqualFold(rrtype, "CNAME") && len(values) > 1 {
+		return &Error{Type: "CNAME", Value: strings.Join(values, ", "), Err: "a CNAME rrset must have a single value"}
+	}
+	for _, value := range values {
+		if _, err := Parse(rrtype, value); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// MustStrings is like Strings but panics if a value is invalid or if
+// values have different types, for values known at compile time
+func MustStrings(values ...Value) []string {
+	s, err := Strings(values...)
+	if err != nil {
+		panic(err)
+	}
+	return s
+}
+
+// Strings validates values and returns their presentation format, as
+// expected by the RrsetValues field of livedns.DomainRecord. An error
+// is returned if a value is invalid or if values have different types.
+func Strings(values ...Value) ([]string, error) {
+	s := make([]string, 0, len(values))
+	for _, v := range values {
+		if v.Type() != values[0].Type() {
+			return nil, fmt.Errorf("Values of a rrset must have the same type (%s and %s)", values[0].Type(), v.Type())
+		}
+		if err := v.Validate(); err != nil {
+			return nil, &Error{Type: v.Type(), Value: v.String(), Err: err.Error()}
+		}
+		s = append(s, v.String())
+	}
+	return s, nil
+}
+
+// validateHostname checks a domain name, which can be relative or
+// fully qualified. The root name "." is accepted when allowRoot is set.
+// Slashes are accepted for the classless reverse delegation names of
+// RFC 2317, such as "0/26.2.0.192.in-addr.arpa.".
+func validateHostname(name string, allowRoot bool) error {
+	if name == "." {
+		if allowRoot {
+			return nil
+		}
+		return fmt.Errorf("the root name is not allowed")
+	}
+	if name == "@" {
+		return nil
+	}
+	trimmed := strings.TrimSuffix(name, ".")
+	if trimmed == "" {
+		return fmt.Errorf("empty name")
+	}
+	if len(trimmed) > 253 {
+		return fmt.Errorf("the name '%s' is longer than 253 characters", name)
+	}
+	for i, label := range strings.Split(trimmed, ".") {
+		if label == "" {
+			return fmt.Errorf("the name '%s' has an empty label", name)
+		}
+		if len(label) > 63 {
+			return fmt.Errorf("the label '%s' is longer than 63 characters", label)
+		}
+		if label == "*" && i == 0 {
+			continue
+		}
+		for _, r := range label {
+			if !(r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || r == '-' || r == '_' || r == '/') {
+				return fmt.Errorf("the name '%s' contains the invalid character '%c'", name, r)
+			}
+		}
+		if label[0] == '-' || label[len(label)-1] == '-' {
+			return fmt.Errorf("the label '%s' starts or ends with a hyphen", label)
+		}
+	}
+	return nil
+}
+
+func hostParser(build func(string) Value) parseFunc {
+	return func(fields []string, _ string) (Value, error) {
+		if len(fields) != 1 {
+			return nil, fmt.Errorf("expected a single domain name")
+		}
+		return build(fields[0]), nil
+	}
+}
+
+func parseUint(s string, bits int, what string) (uint64, error) {
+	v, err := strconv.ParseUint(s, 10, bits)
+	if err != nil {
+		return 0, fmt.Errorf("invalid %s '%s'", what, s)
+	}
+	return v, nil
+}
+
+func expectFields(fields []string, n int) error {
+	if len(fields) != n {
+		return fmt.Errorf("expected %d fields (got %d)", n, len(fields))
+	}
+	return nil
+}
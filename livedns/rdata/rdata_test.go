@@ -0,0 +1,146 @@
+package rdata_test
+
+import (
+	"net"
+	"strings"
+	"testing"
+
+	"github.com/go-gandi/go-gandi/livedns/rdata"
+)
+
+func TestString(t *testing.T) {
+	for _, tc := range []struct {
+		value    rdata.Value
+		expected string
+	}{
+		{rdata.A{IP: net.ParseIP("192.0.2.1")}, "192.0.2.1"},
+		{rdata.MX{Preference: 10, Host: "spool.mail.gandi.net."}, "10 spool.mail.gandi.net."},
+		{rdata.SRV{Priority: 0, Weight: 1, Port: 993, Target: "mail.gandi.net."}, "0 1 993 mail.gandi.net."},
+		{rdata.CAA{Flags: 0, Tag: "issue", Value: "letsencrypt.org"}, `0 issue "letsencrypt.org"`},
+		{rdata.TXT{Text: `say "hi"`}, `"say \"hi\""`},
+		{rdata.NewLOC(52.373056, -4.8925, -2), "52 22 23.002 N 4 53 33.000 W -2.00m 1.00m 10000.00m 10.00m"},
+		{rdata.HTTPS{Priority: 1, Target: ".", Params: []rdata.SvcParam{{Key: "alpn", Value: "h2,h3"}}}, "1 . alpn=h2,h3"},
+	} {
+		if s := tc.value.String(); s != tc.expected {
+			t.Fatalf("%s value should be '%s' (while it is '%s')", tc.value.Type(), tc.expected, s)
+		}
+		parsed, err := rdata.Parse(tc.value.Type(), tc.expected)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if parsed.String() != tc.expected {
+			t.Fatalf("Parsed %s value should be '%s' (while it is '%s')", tc.value.Type(), tc.expected, parsed)
+		}
+	}
+}
+
+func TestTXTChunking(t *testing.T) {
+	long := strings.Repeat("a", 300)
+	value := rdata.TXT{Text: long}.String()
+	if value != `"`+long[:255]+`" "`+long[255:]+`"` {
+		t.Fatalf("The text should be split into chunks of 255 bytes (got %s)", value)
+	}
+	parsed, err := rdata.Parse("TXT", value)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if parsed.(rdata.TXT).Text != long {
+		t.Fatal("Chunks should be joined when parsed")
+	}
+}
+
+func TestValidate(t *testing.T) {
+	for _, tc := range []struct {
+		rrtype string
+		values []string
+	}{
+		{"A", []string{"2001:db8::1"}},
+		{"AAAA", []string{"192.0.2.1"}},
+		{"MX", []string{"spool.mail.gandi.net."}},
+		{"SRV", []string{"0 1 70000 mail.gandi.net."}},
+		{"CAA", []string{`0 is-sue "letsencrypt.org"`}},
+		{"CNAME", []string{"a.example.com.", "b.example.com."}},
+		{"DS", []string{"12345 13 2 ABCD"}},
+		{"SSHFP", []string{"4 2 abcd"}},
+		{"TXT", []string{`"` + strings.Repeat("a", 256) + `"`}},
+		{"HTTPS", []string{"0 example.com. alpn=h2"}},
+	} {
+		if err := rdata.Validate(tc.rrtype, tc.values); err == nil {
+			t.Fatalf("%s values %v should be rejected", tc.rrtype, tc.values)
+		}
+	}
+	if err := rdata.Validate("UNKNOWN", []string{"anything"}); err != nil {
+		t.Fatalf("Unsupported types should not be validated (got %s)", err)
+	}
+}
+
+func TestStrings(t *testing.T) {
+	values, err := rdata.Strings(rdata.MX{Preference: 10, Host: "spool.mail.gandi.net."}, rdata.MX{Preference: 50, Host: "fb.mail.gandi.net."})
+	if err != nil || len(values) != 2 || values[1] != "50 fb.mail.gandi.net." {
+		t.Fatalf("Unexpected values %v (error %v)", values, err)
+	}
+	if _, err := rdata.Strings(rdata.MX{Preference: 10, Host: "mx.example.com."}, rdata.CNAME{Target: "example.com."}); err == nil {
+		t.Fatal("Values of different types should be rejected")
+	}
+	defer func() {
+		if recover() == nil {
+			t.Fatal("MustStrings should panic on an invalid value")
+		}
+	}()
+	rdata.MustStrings(rdata.MX{Preference: 10, Host: "bad host"})
+}
+
+func TestParseNames(t *testing.T) {
+	for _, tc := range []struct {
+		rrtype, value string
+	}{
+		// Classless reverse delegation (RFC 2317)
+		{"CNAME", "1.0/26.2.0.192.in-addr.arpa."},
+		{"NS", "ns1.example.com."},
+		{"PTR", "host.example.com."},
+		{"CNAME", "*.example.com."},
+	} {
+		if _, err := rdata.Parse(tc.rrtype, tc.value); err != nil {
+			t.Errorf("%s value '%s' should be accepted (got %s)", tc.rrtype, tc.value, err)
+		}
+	}
+	for _, value := range []string{"bad host.", "a..example.com.", "-a.example.com."} {
+		if _, err := rdata.Parse("CNAME", value); err == nil {
+			t.Errorf("CNAME value '%s' should be rejected", value)
+		}
+	}
+}
+
+func TestParseSvcParams(t *testing.T) {
+	for _, tc := range []struct {
+		value  string
+		params []rdata.SvcParam
+	}{
+		{"1 . alpn=h2,h3", []rdata.SvcParam{{Key: "alpn", Value: "h2,h3"}}},
+		{"1 . alpn=h2 no-default-alpn port=8443", []rdata.SvcParam{{Key: "alpn", Value: "h2"}, {Key: "no-default-alpn"}, {Key: "port", Value: "8443"}}},
+		{`1 svc.example.com. alpn="h2,h3" key65000="a b  c"`, []rdata.SvcParam{{Key: "alpn", Value: "h2,h3"}, {Key: "key65000", Value: "a b  c"}}},
+		{`1 . key65001="say \"hi\""`, []rdata.SvcParam{{Key: "key65001", Value: `say "hi"`}}},
+	} {
+		v, err := rdata.Parse("HTTPS", tc.value)
+		if err != nil {
+			t.Errorf("'%s' should be accepted (got %s)", tc.value, err)
+			continue
+		}
+		params := v.(rdata.HTTPS).Params
+		if len(params) != len(tc.params) {
+			t.Errorf("'%s' should have %d parameters (got %v)", tc.value, len(tc.params), params)
+			continue
+		}
+		for i := range params {
+			if params[i] != tc.params[i] {
+				t.Errorf("'%s' parameter %d should be %v (got %v)", tc.value, i, tc.params[i], params[i])
+			}
+		}
+		if _, err := rdata.Parse("HTTPS", v.String()); err != nil {
+			t.Errorf("'%s' should be parsed back (got %s)", v, err)
+		}
+	}
+	if _, err := rdata.Parse("HTTPS", `1 . key65000="unterminated`); err == nil {
+		t.Error("An unterminated quoted value should be rejected")
+	}
+}
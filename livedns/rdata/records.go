@@ -0,0 +1,387 @@
+package rdata
+
+import (
+	"encoding/hex"
+	"fmt"
+	"net"
+	"strings"
+)
+
+// A is an IPv4 address record
+type A struct {
+	IP net.IP
+}
+
+// Type returns "A"
+func (v A) Type() string { return "A" }
+
+func (v A) String() string { return v.IP.String() }
+
+// Validate checks the address is an IPv4 address
+func (v A) Validate() error {
+	if v.IP.To4() == nil {
+		return fmt.Errorf("'%s' is not an IPv4 address", v.IP)
+	}
+	return nil
+}
+
+func parseA(fields []string, _ string) (Value, error) {
+	if err := expectFields(fields, 1); err != nil {
+		return nil, err
+	}
+	ip := net.ParseIP(fields[0])
+	if ip == nil || ip.To4() == nil || strings.Contains(fields[0], ":") {
+		return nil, fmt.Errorf("not an IPv4 address")
+	}
+	return A{IP: ip}, nil
+}
+
+// AAAA is an IPv6 address record
+type AAAA struct {
+	IP net.IP
+}
+
+// Type returns "AAAA"
+func (v AAAA) Type() string { return "AAAA" }
+
+func (v AAAA) String() string { return v.IP.String() }
+
+// Validate checks the address is an IPv6 address
+func (v AAAA) Validate() error {
+	if v.IP == nil || v.IP.To16() == nil || v.IP.To4() != nil {
+		return fmt.Errorf("'%s' is not an IPv6 address", v.IP)
+	}
+	return nil
+}
+
+func parseAAAA(fields []string, _ string) (Value, error) {
+	if err := expectFields(fields, 1); err != nil {
+		return nil, err
+	}
+	ip := net.ParseIP(fields[0])
+	if ip == nil || !strings.Contains(fields[0], ":") {
+		return nil, fmt.Errorf("not an IPv6 address")
+	}
+	return AAAA{IP: ip}, nil
+}
+
+// CNAME is a canonical name record
+type CNAME struct {
+	Target string
+}
+
+// Type returns "CNAME"
+func (v CNAME) Type() string { return "CNAME" }
+
+func (v CNAME) String() string { return v.Target }
+
+// Validate checks the target is a valid domain name
+func (v CNAME) Validate() error { return validateHostname(v.Target, false) }
+
+// NS is a name server record
+type NS struct {
+	Host string
+}
+
+// Type returns "NS"
+func (v NS) Type() string { return "NS" }
+
+func (v NS) String() string { return v.Host }
+
+// Validate checks the host is a valid domain name
+func (v NS) Validate() error { return validateHostname(v.Host, false) }
+
+// PTR is a pointer record
+type PTR struct {
+	Host string
+}
+
+// Type returns "PTR"
+func (v PTR) Type() string { return "PTR" }
+
+func (v PTR) String() string { return v.Host }
+
+// Validate checks the host is a valid domain name
+func (v PTR) Validate() error { return validateHostname(v.Host, false) }
+
+// ALIAS is the LiveDNS apex alias record, resolved by the Gandi
+// nameservers to the addresses of its target
+type ALIAS struct {
+	Target string
+}
+
+// Type returns "ALIAS"
+func (v ALIAS) Type() string { return "ALIAS" }
+
+func (v ALIAS) String() string { return v.Target }
+
+// Validate checks the target is a valid domain name
+func (v ALIAS) Validate() error { return validateHostname(v.Target, false) }
+
+// DNAME is a delegation name record
+type DNAME struct {
+	Target string
+}
+
+// Type returns "DNAME"
+func (v DNAME) Type() string { return "DNAME" }
+
+func (v DNAME) String() string { return v.Target }
+
+// Validate checks the target is a valid domain name
+func (v DNAME) Validate() error { return validateHostname(v.Target, false) }
+
+// MX is a mail exchange record
+type MX struct {
+	Preference uint16
+	Host       string
+}
+
+// Type returns "MX"
+func (v MX) Type() string { return "MX" }
+
+func (v MX) String() string { return fmt.Sprintf("%d %s", v.Preference, v.Host) }
+
+// Validate checks the host is a valid domain name. The root name is
+// accepted for null MX records (RFC 7505).
+func (v MX) Validate() error { return validateHostname(v.Host, true) }
+
+func parseMX(fields []string, _ string) (Value, error) {
+	if err := expectFields(fields, 2); err != nil {
+		return nil, err
+	}
+	pref, err := parseUint(fields[0], 16, "preference")
+	if err != nil {
+		return nil, err
+	}
+	return MX{Preference: uint16(pref), Host: fields[1]}, nil
+}
+
+// SRV is a service locator record
+type SRV struct {
+	Priority uint16
+	Weight   uint16
+	Port     uint16
+	Target   string
+}
+
+// Type returns "SRV"
+func (v SRV) Type() string { return "SRV" }
+
+func (v SRV) String() string {
+	return fmt.Sprintf("%d %d %d %s", v.Priority, v.Weight, v.Port, v.Target)
+}
+
+// Validate checks the target is a valid domain name. The root name
+// means the service is not available (RFC 2782).
+func (v SRV) Validate() error { return validateHostname(v.Target, true) }
+
+func parseSRV(fields []string, _ string) (Value, error) {
+	if err := expectFields(fields, 4); err != nil {
+		return nil, err
+	}
+	var n [3]uint16
+	for i, what := range []string{"priority", "weight", "port"} {
+		v, err := parseUint(fields[i], 16, what)
+		if err != nil {
+			return nil, err
+		}
+		n[i] = uint16(v)
+	}
+	return SRV{Priority: n[0], Weight: n[1], Port: n[2], Target: fields[3]}, nil
+}
+
+// CAA is a certification authority authorization record
+type CAA struct {
+	Flags uint8
+	Tag   string
+	Value string
+}
+
+// Type returns "CAA"
+func (v CAA) Type() string { return "CAA" }
+
+func (v CAA) String() string {
+	return fmt.Sprintf("%d %s %s", v.Flags, v.Tag, quote(v.Value))
+}
+
+// Validate checks the tag is made of letters and digits, as required
+// by RFC 8659
+func (v CAA) Validate() error {
+	if v.Tag == "" || len(v.Tag) > 15 {
+		return fmt.Errorf("the tag must have between 1 and 15 characters")
+	}
+	for _, r := range v.Tag {
+		if !(r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9') {
+			return fmt.Errorf("the tag '%s' must only contain letters and digits", v.Tag)
+		}
+	}
+	if len(v.Value) > 255 {
+		return fmt.Errorf("the value is longer than 255 bytes")
+	}
+	return nil
+}
+
+func parseCAA(fields []string, raw string) (Value, error) {
+	if len(fields) < 3 {
+		return nil, fmt.Errorf("expected flags, tag and value")
+	}
+	flags, err := parseUint(fields[0], 8, "flags")
+	if err != nil {
+		return nil, err
+	}
+	// The value is what follows the tag, and may contain spaces
+	rest := strings.TrimSpace(raw)
+	for i := 0; i < 2; i++ {
+		rest = strings.TrimSpace(rest[len(fields[i]):])
+	}
+	value := rest
+	if strings.HasPrefix(rest, `"`) {
+		strs, err := unquote(rest)
+		if err != nil {
+			return nil, err
+		}
+		value = strings.Join(strs, "")
+	}
+	return CAA{Flags: uint8(flags), Tag: fields[1], Value: value}, nil
+}
+
+// TLSA is a TLS certificate association record (DANE)
+type TLSA struct {
+	Usage        uint8
+	Selector     uint8
+	MatchingType uint8
+	// Certificate is the hexadecimal certificate association data
+	Certificate string
+}
+
+// Type returns "TLSA"
+func (v TLSA) Type() string { return "TLSA" }
+
+func (v TLSA) String() string {
+	return fmt.Sprintf("%d %d %d %s", v.Usage, v.Selector, v.MatchingType, strings.ToLower(v.Certificate))
+}
+
+// Validate checks the fields are in their ranges and the length of
+// the digest matches the matching type
+func (v TLSA) Validate() error {
+	if v.Usage > 3 {
+		return fmt.Errorf("the certificate usage must be between 0 and 3")
+	}
+	if v.Selector > 1 {
+		return fmt.Errorf("the selector must be 0 or 1")
+	}
+	return validateDigest(v.Certificate, map[uint8]int{1: 32, 2: 64}, v.MatchingType, "matching type")
+}
+
+func parseTLSA(fields []string, _ string) (Value, error) {
+	if len(fields) < 4 {
+		return nil, fmt.Errorf("expected usage, selector, matching type and data")
+	}
+	var n [3]uint8
+	for i, what := range []string{"usage", "selector", "matching type"} {
+		v, err := parseUint(fields[i], 8, what)
+		if err != nil {
+			return nil, err
+		}
+		n[i] = uint8(v)
+	}
+	return TLSA{Usage: n[0], Selector: n[1], MatchingType: n[2], Certificate: strings.Join(fields[3:], "")}, nil
+}
+
+// SSHFP is a SSH public key fingerprint record
+type SSHFP struct {
+	Algorithm       uint8
+	FingerprintType uint8
+	// Fingerprint is the hexadecimal fingerprint
+	Fingerprint string
+}
+
+// Type returns "SSHFP"
+func (v SSHFP) Type() string { return "SSHFP" }
+
+func (v SSHFP) String() string {
+	return fmt.Sprintf("%d %d %s", v.Algorithm, v.FingerprintType, strings.ToLower(v.Fingerprint))
+}
+
+// Validate checks the length of the fingerprint matches its type
+func (v SSHFP) Validate() error {
+	if v.Algorithm == 0 {
+		return fmt.Errorf("the algorithm must not be 0")
+	}
+	if v.FingerprintType != 1 && v.FingerprintType != 2 {
+		return fmt.Errorf("the fingerprint type must be 1 (SHA-1) or 2 (SHA-256)")
+	}
+	return validateDigest(v.Fingerprint, map[uint8]int{1: 20, 2: 32}, v.FingerprintType, "fingerprint type")
+}
+
+func parseSSHFP(fields []string, _ string) (Value, error) {
+	if err := expectFields(fields, 3); err != nil {
+		return nil, err
+	}
+	alg, err := parseUint(fields[0], 8, "algorithm")
+	if err != nil {
+		return nil, err
+	}
+	typ, err := parseUint(fields[1], 8, "fingerprint type")
+	if err != nil {
+		return nil, err
+	}
+	return SSHFP{Algorithm: uint8(alg), FingerprintType: uint8(typ), Fingerprint: fields[2]}, nil
+}
+
+// DS is a delegation signer record
+type DS struct {
+	KeyTag     uint16
+	Algorithm  uint8
+	DigestType uint8
+	// Digest is the hexadecimal digest of the DNSKEY
+	Digest string
+}
+
+// Type returns "DS"
+func (v DS) Type() string { return "DS" }
+
+func (v DS) String() string {
+	return fmt.Sprintf("%d %d %d %s", v.KeyTag, v.Algorithm, v.DigestType, strings.ToUpper(v.Digest))
+}
+
+// Validate checks the length of the digest matches its type
+func (v DS) Validate() error {
+	if v.DigestType == 0 {
+		return fmt.Errorf("the digest type must not be 0")
+	}
+	return validateDigest(v.Digest, map[uint8]int{1: 20, 2: 32, 4: 48}, v.DigestType, "digest type")
+}
+
+func parseDS(fields []string, _ string) (Value, error) {
+	if len(fields) < 4 {
+		return nil, fmt.Errorf("expected key tag, algorithm, digest type and digest")
+	}
+	tag, err := parseUint(fields[0], 16, "key tag")
+	if err != nil {
+		return nil, err
+	}
+	alg, err := parseUint(fields[1], 8, "algorithm")
+	if err != nil {
+		return nil, err
+	}
+	typ, err := parseUint(fields[2], 8, "digest type")
+	if err != nil {
+		return nil, err
+	}
+	return DS{KeyTag: uint16(tag), Algorithm: uint8(alg), DigestType: uint8(typ), Digest: strings.Join(fields[3:], "")}, nil
+}
+
+// validateDigest checks s is an hexadecimal string whose length is the
+// one expected for the digest type, if the type is known
+func validateDigest(s string, sizes map[uint8]int, typ uint8, what string) error {
+	b, err := hex.DecodeString(s)
+	if err != nil || len(b) == 0 {
+		return fmt.Errorf("'%s' is not an hexadecimal string", s)
+	}
+	if size, ok := sizes[typ]; ok && len(b) != size {
+		return fmt.Errorf("the digest should be %d bytes long for the %s %d (got %d)", size, what, typ, len(b))
+	}
+	return nil
+}
@@ -0,0 +1,196 @@
+package rdata
+
+import (
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// SvcParam is a key=value parameter of SVCB and HTTPS records, such
+// as "alpn=h2,h3". Value is empty for flags like "no-default-alpn".
+type SvcParam struct {
+	Key   string
+	Value string
+}
+
+func (p SvcParam) String() string {
+	if p.Value == "" {
+		return p.Key
+	}
+	if strings.ContainsAny(p.Value, " \t\"") {
+		return p.Key + "=" + escape(p.Value)
+	}
+	return p.Key + "=" + p.Value
+}
+
+// SVCB is a service binding record (RFC 9460). A zero priority makes
+// it an alias to the target, which must then have no parameter.
+type SVCB struct {
+	Priority uint16
+	Target   string
+	Params   []SvcParam
+}
+
+// Type returns "SVCB"
+func (v SVCB) Type() string { return "SVCB" }
+
+func (v SVCB) String() string { return formatSVCB(v.Priority, v.Target, v.Params) }
+
+// Validate checks the target and the parameters
+func (v SVCB) Validate() error { return validateSVCB(v.Priority, v.Target, v.Params) }
+
+// HTTPS is the service binding record dedicated to HTTPS (RFC 9460)
+type HTTPS struct {
+	Priority uint16
+	Target   string
+	Params   []SvcParam
+}
+
+// Type returns "HTTPS"
+func (v HTTPS) Type() string { return "HTTPS" }
+
+func (v HTTPS) String() string { return formatSVCB(v.Priority, v.Target, v.Params) }
+
+// Validate checks the target and the parameters
+func (v HTTPS) Validate() error { return validateSVCB(v.Priority, v.Target, v.Params) }
+
+func formatSVCB(priority uint16, target string, params []SvcParam) string {
+	parts := []string{strconv.Itoa(int(priority)), target}
+	for _, p := range params {
+		parts = append(parts, p.String())
+	}
+	return strings.Join(parts, " ")
+}
+
+func validateSVCB(priority uint16, target string, params []SvcParam) error {
+	if err := validateHostname(target, true); err != nil {
+		return err
+	}
+	if priority == 0 && len(params) > 0 {
+		return fmt.Errorf("an alias record (priority 0) must not have parameters")
+	}
+	seen := map[string]bool{}
+	for _, p := range params {
+		key := strings.ToLower(p.Key)
+		if seen[key] {
+			return fmt.Errorf("the parameter '%s' is duplicated", key)
+		}
+		seen[key] = true
+		if err := validateSvcParam(key, p.Value); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func validateSvcParam(key, value string) error {
+	switch key {
+	case "mandatory", "alpn":
+		if value == "" {
+			return fmt.Errorf("the parameter '%s' requires a value", key)
+		}
+	case "no-default-alpn":
+		if value != "" {
+			return fmt.Errorf("the parameter '%s' does not take a value", key)
+		}
+	case "port":
+		if _, err := strconv.ParseUint(value, 10, 16); err != nil {
+			return fmt.Errorf("invalid port '%s'", value)
+		}
+	case "ipv4hint", "ipv6hint":
+		for _, addr := range strings.Split(value, ",") {
+			ip := net.ParseIP(addr)
+			if ip == nil || (key == "ipv4hint") != (ip.To4() != nil && !strings.Contains(addr, ":")) {
+				return fmt.Errorf("invalid address '%s' in %s", addr, key)
+			}
+		}
+	case "ech":
+		if value == "" {
+			return fmt.Errorf("the parameter 'ech' requires a value")
+		}
+	default:
+		if !strings.HasPrefix(key, "key") {
+			return fmt.Errorf("unknown parameter '%s'", key)
+		}
+		if _, err := strconv.ParseUint(key[3:], 10, 16); err != nil {
+			return fmt.Errorf("unknown parameter '%s'", key)
+		}
+	}
+	return nil
+}
+
+func svcbParser(rrtype string) parseFunc {
+	return func(fields []string, raw string) (Value, error) {
+		if len(fields) < 2 {
+			return nil, fmt.Errorf("expected a priority and a target")
+		}
+		priority, err := parseUint(fields[0], 16, "priority")
+		if err != nil {
+			return nil, err
+		}
+		// The parameters are split from the raw value since quoted
+		// values may contain spaces
+		rest := raw
+		for _, field := range fields[:2] {
+			rest = strings.TrimLeftFunc(rest, unicode.IsSpace)[len(field):]
+		}
+		params, err := parseSvcParams(rest)
+		if err != nil {
+			return nil, err
+		}
+		if rrtype == "HTTPS" {
+			return HTTPS{Priority: uint16(priority), Target: fields[1], Params: params}, nil
+		}
+		return SVCB{Priority: uint16(priority), Target: fields[1], Params: params}, nil
+	}
+}
+
+// parseSvcParams splits key=value parameters separated by spaces. A
+// value can be quoted, and then contain spaces and escapes.
+func parseSvcParams(s string) ([]SvcParam, error) {
+	var params []SvcParam
+	i := 0
+	for {
+		for i < len(s) && (s[i] == ' ' || s[i] == '\t') {
+			i++
+		}
+		if i == len(s) {
+			return params, nil
+		}
+		start := i
+		for i < len(s) && s[i] != '=' && s[i] != ' ' && s[i] != '\t' {
+			i++
+		}
+		p := SvcParam{Key: s[start:i]}
+		if i < len(s) && s[i] == '=' {
+			i++
+			start = i
+			if i < len(s) && s[i] == '"' {
+				// Find the closing quote, skipping the escaped
+				// characters
+				for i++; i < len(s) && s[i] != '"'; i++ {
+					if s[i] == '\\' {
+						i++
+					}
+				}
+				if i >= len(s) {
+					return nil, fmt.Errorf("unterminated quoted value of the parameter '%s'", p.Key)
+				}
+				i++
+				strs, err := unquote(s[start:i])
+				if err != nil {
+					return nil, err
+				}
+				p.Value = strings.Join(strs, "")
+			} else {
+				for i < len(s) && s[i] != ' ' && s[i] != '\t' {
+					i++
+				}
+				p.Value = s[start:i]
+			}
+		}
+		params = append(params, p)
+	}
+}
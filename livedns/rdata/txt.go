@@ -0,0 +1,159 @@
+package rdata
+
+import (
+	"fmt"
+	"strings"
+)
+
+// maxStringLength is the maximum length of a DNS character string
+const maxStringLength = 255
+
+// TXT is a text record. Texts longer than 255 bytes are split into
+// several character strings by String, as required by RFC 1035.
+type TXT struct {
+	Text string
+}
+
+// Type returns "TXT"
+func (v TXT) Type() string { return "TXT" }
+
+func (v TXT) String() string { return quote(v.Text) }
+
+// Validate always succeeds since any text can be chunked
+func (v TXT) Validate() error { return nil }
+
+// SPF is the deprecated SPF record type. Use a TXT record instead.
+type SPF struct {
+	Text string
+}
+
+// Type returns "SPF"
+func (v SPF) Type() string { return "SPF" }
+
+func (v SPF) String() string { return quote(v.Text) }
+
+// Validate checks the text is a SPF policy
+func (v SPF) Validate() error {
+	if !strings.HasPrefix(v.Text, "v=spf1") {
+		return fmt.Errorf("a SPF policy must start with 'v=spf1'")
+	}
+	return nil
+}
+
+func parseTXT(raw string, spf bool) (Value, error) {
+	raw = strings.TrimSpace(raw)
+	text := raw
+	if strings.HasPrefix(raw, `"`) {
+		strs, err := unquote(raw)
+		if err != nil {
+			return nil, err
+		}
+		for _, s := range strs {
+			if len(s) > maxStringLength {
+				return nil, fmt.Errorf("a character string is longer than %d bytes", maxStringLength)
+			}
+		}
+		text = strings.Join(strs, "")
+	}
+	if spf {
+		return SPF{Text: text}, nil
+	}
+	return TXT{Text: text}, nil
+}
+
+// quote returns the text as a sequence of quoted character strings of
+// at most 255 bytes, escaping quotes and backslashes
+func quote(text string) string {
+	var chunks []string
+	for {
+		n := len(text)
+		if n > maxStringLength {
+			n = maxStringLength
+		}
+		chunks = append(chunks, escape(text[:n]))
+		text = text[n:]
+		if text == "" {
+			break
+		}
+	}
+	return strings.Join(chunks, " ")
+}
+
+func escape(s string) string {
+	var b strings.Builder
+	b.WriteByte('"')
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case c == '"' || c == '\\':
+			b.WriteByte('\\')
+			b.WriteByte(c)
+		case c < 0x20 || c == 0x7f:
+			fmt.Fprintf(&b, "\\%03d", c)
+		default:
+			b.WriteByte(c)
+		}
+	}
+	b.WriteByte('"')
+	return b.String()
+}
+
+// unquote decodes a sequence of quoted character strings, handling
+// the \X and \DDD escapes
+func unquote(s string) ([]string, error) {
+	var strs []string
+	i := 0
+	for {
+		for i < len(s) && (s[i] == ' ' || s[i] == '\t') {
+			i++
+		}
+		if i == len(s) {
+			return strs, nil
+		}
+		if s[i] != '"' {
+			return nil, fmt.Errorf("expected a quoted string at position %d", i)
+		}
+		i++
+		var b []byte
+		closed := false
+		for i < len(s) {
+			c := s[i]
+			i++
+			if c == '"' {
+				closed = true
+				break
+			}
+			if c != '\\' {
+				b = append(b, c)
+				continue
+			}
+			if i == len(s) {
+				break
+			}
+			if i+3 <= len(s) && isDigits(s[i:i+3]) {
+				d := int(s[i]-'0')*100 + int(s[i+1]-'0')*10 + int(s[i+2]-'0')
+				if d > 255 {
+					return nil, fmt.Errorf("invalid escape sequence '\\%s'", s[i:i+3])
+				}
+				b = append(b, byte(d))
+				i += 3
+				continue
+			}
+			b = append(b, s[i])
+			i++
+		}
+		if !closed {
+			return nil, fmt.Errorf("unterminated quoted string")
+		}
+		strs = append(strs, string(b))
+	}
+}
+
+func isDigits(s string) bool {
+	for i := 0; i < len(s); i++ {
+		if s[i] < '0' || s[i] > '9' {
+			return false
+		}
+	}
+	return true
+}
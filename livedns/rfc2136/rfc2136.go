@@ -0,0 +1,416 @@
+// Package rfc2136 is a DNS server accepting dynamic updates (RFC
+// 2136) authenticated with TSIG, and translating them into LiveDNS
+// API calls. It lets tools such as external-dns, certbot-dns-rfc2136
+// or ISC dhcpd manage LiveDNS zones without any Gandi specific plugin.
+//
+// Prerequisites are checked against the current records before the
+// updates are applied one rrset at a time. Since LiveDNS has no
+// transaction, an update failing in the middle of a message leaves
+// the previous ones applied. The SOA record is managed by LiveDNS and
+// updates to it are ignored, as are deletions of the apex NS records.
+package rfc2136
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/go-gandi/go-gandi/livedns"
+	"github.com/go-gandi/go-gandi/types"
+	"github.com/miekg/dns"
+)
+
+// API is the subset of the LiveDNS client used by the gateway.
+// *livedns.LiveDNS implements it.
+type API interface {
+	GetDomainRecordsByName(fqdn, name string) ([]livedns.DomainRecord, error)
+	GetDomainRecordByNameAndType(fqdn, name, recordtype string) (livedns.DomainRecord, error)
+	AddRecordValuesWithTTL(fqdn, name, recordtype string, ttl int, values []string) (livedns.DomainRecord, error)
+	RemoveRecordValues(fqdn, name, recordtype string, values []string) (livedns.DomainRecord, error)
+	DeleteDomainRecord(fqdn, name, recordtype string) error
+}
+
+var _ API = (*livedns.LiveDNS)(nil)
+
+// Gateway handles dynamic update messages
+type Gateway struct {
+	API API
+	// Zones maps the zones found in update messages to LiveDNS
+	// domains, for instance "example.com" to "example.com". Zones
+	// which are not in this map are refused.
+	Zones map[string]string
+	// TSIGSecrets maps the TSIG key names to their base64 secrets.
+	// Unsigned messages are refused unless AllowUnsigned is set.
+	TSIGSecrets map[string]string
+	// AllowUnsigned accepts messages without TSIG signature. It
+	// should only be used for tests or behind a trusted network.
+	AllowUnsigned bool
+	// Logger receives a line per processed message when not nil
+	Logger *log.Logger
+}
+
+// NewGateway returns a gateway for the given zones and TSIG secrets.
+// Zone and key names are canonicalized.
+func NewGateway(api API, zones map[string]string, secrets map[string]string) *Gateway {
+	g := &Gateway{API: api, Zones: map[string]string{}, TSIGSecrets: map[string]string{}}
+	for zone, domain := range zones {
+		g.Zones[dns.CanonicalName(zone)] = strings.TrimSuffix(domain, ".")
+	}
+	for name, secret := range secrets {
+		g.TSIGSecrets[dns.CanonicalName(name)] = secret
+	}
+	return g
+}
+
+// Server returns a DNS server for the network ("udp" or "tcp")
+// serving the gateway on addr
+func (g *Gateway) Server(network, addr string) *dns.Server {
+	return &dns.Server{Addr: addr, Net: network, Handler: g, TsigSecret: g.TSIGSecrets, MsgAcceptFunc: acceptUpdate}
+}
+
+// acceptUpdate lets update messages reach the handler, which the
+// default function of miekg/dns rejects
+func acceptUpdate(dh dns.Header) dns.MsgAcceptAction {
+	if opcode := int(dh.Bits>>11) & 0xF; opcode == dns.OpcodeUpdate {
+		return dns.MsgAccept
+	}
+	return dns.DefaultMsgAcceptFunc(dh)
+}
+
+// ListenAndServe serves the gateway over UDP and TCP until the
+// context is canceled or a server fails
+func (g *Gateway) ListenAndServe(ctx context.Context, addr string) error {
+	servers := []*dns.Server{g.Server("udp", addr), g.Server("tcp", addr)}
+	errs := make(chan error, len(servers))
+	for _, server := range servers {
+		go func(server *dns.Server) {
+			errs <- server.ListenAndServe()
+		}(server)
+	}
+	var err error
+	select {
+	case <-ctx.Done():
+		err = ctx.Err()
+	case err = <-errs:
+	}
+	for _, server := range servers {
+		_ = server.Shutdown()
+	}
+	return err
+}
+
+// ServeDNS implements dns.Handler
+func (g *Gateway) ServeDNS(w dns.ResponseWriter, r *dns.Msg) {
+	m := new(dns.Msg)
+	m.SetReply(r)
+	m.Rcode = g.handle(w, r)
+	if tsig := r.IsTsig(); tsig != nil && w.TsigStatus() == nil {
+		m.SetTsig(tsig.Hdr.Name, tsig.Algorithm, 300, time.Now().Unix())
+	}
+	if err := w.WriteMsg(m); err != nil {
+		g.logf("Fail to write the response to %s: %s", w.RemoteAddr(), err)
+	}
+}
+
+func (g *Gateway) handle(w dns.ResponseWriter, r *dns.Msg) int {
+	if r.Opcode != dns.OpcodeUpdate {
+		return dns.RcodeNotImplemented
+	}
+	if tsig := r.IsTsig(); tsig != nil {
+		if err := w.TsigStatus(); err != nil {
+			g.logf("Refusing update from %s: TSIG error: %s", w.RemoteAddr(), err)
+			return dns.RcodeNotAuth
+		}
+	} else if !g.AllowUnsigned {
+		g.logf("Refusing unsigned update from %s", w.RemoteAddr())
+		return dns.RcodeRefused
+	}
+	if len(r.Question) != 1 || r.Question[0].Qtype != dns.TypeSOA {
+		return dns.RcodeFormatError
+	}
+	zone := dns.CanonicalName(r.Question[0].Name)
+	domain, ok := g.Zones[zone]
+	if !ok {
+		g.logf("Refusing update of unknown zone %s from %s", zone, w.RemoteAddr())
+		return dns.RcodeNotAuth
+	}
+	u := update{api: g.API, zone: zone, domain: domain}
+	if rcode := u.checkPrerequisites(r.Answer); rcode != dns.RcodeSuccess {
+		return rcode
+	}
+	if rcode := u.checkUpdates(r.Ns); rcode != dns.RcodeSuccess {
+		return rcode
+	}
+	for _, rr := range r.Ns {
+		if err := u.apply(rr); err != nil {
+			g.logf("Fail to apply '%s' to %s: %s", rr, domain, err)
+			return dns.RcodeServerFailure
+		}
+	}
+	g.logf("Applied %d updates to %s from %s", len(r.Ns), domain, w.RemoteAddr())
+	return dns.RcodeSuccess
+}
+
+func (g *Gateway) logf(format string, args ...interface{}) {
+	if g.Logger != nil {
+		g.Logger.Printf(format, args...)
+	}
+}
+
+type update struct {
+	api    API
+	zone   string
+	domain string
+}
+
+// relative returns the LiveDNS name of an owner name, and false if
+// it is outside of the zone
+func (u *update) relative(name string) (string, bool) {
+	name = dns.CanonicalName(name)
+	if name == u.zone {
+		return "@", true
+	}
+	if !dns.IsSubDomain(u.zone, name) {
+		return "", false
+	}
+	return strings.TrimSuffix(name, "."+u.zone), true
+}
+
+// rrset returns the records of a rrset parsed as resource records,
+// and nil if the rrset does not exist
+func (u *update) rrset(name, rrtype string) (livedns.DomainRecord, []dns.RR, error) {
+	record, err := u.api.GetDomainRecordByNameAndType(u.domain, name, rrtype)
+	if isNotFound(err) {
+		return livedns.DomainRecord{}, nil, nil
+	}
+	if err != nil {
+		return livedns.DomainRecord{}, nil, err
+	}
+	rrs := make([]dns.RR, 0, len(record.RrsetValues))
+	for _, value := range record.RrsetValues {
+		rr, err := u.parse(name, rrtype, value)
+		if err != nil {
+			return record, nil, err
+		}
+		rrs = append(rrs, rr)
+	}
+	return record, rrs, nil
+}
+
+// parse returns the resource record of a LiveDNS value. Relative
+// names in the value are relative to the zone.
+func (u *update) parse(name, rrtype, value string) (dns.RR, error) {
+	owner := u.zone
+	if name != "@" {
+		owner = name + "." + u.zone
+	}
+	parser := dns.NewZoneParser(strings.NewReader(fmt.Sprintf("%s 0 IN %s %s", owner, rrtype, value)), u.zone, "")
+	rr, ok := parser.Next()
+	if !ok {
+		if err := parser.Err(); err != nil {
+			return nil, err
+		}
+		return nil, fmt.Errorf("Cannot parse the %s value '%s'", rrtype, value)
+	}
+	return rr, nil
+}
+
+// value returns the LiveDNS value of a resource record
+func value(rr dns.RR) string {
+	return strings.TrimPrefix(rr.String(), rr.Header().String())
+}
+
+func (u *update) checkPrerequisites(prereqs []dns.RR) int {
+	// Value dependent prerequisites are grouped by rrset
+	expected := map[[2]string][]dns.RR{}
+	var order [][2]string
+	for _, rr := range prereqs {
+		h := rr.Header()
+		if h.Ttl != 0 {
+			return dns.RcodeFormatError
+		}
+		name, ok := u.relative(h.Name)
+		if !ok {
+			return dns.RcodeNotZone
+		}
+		rrtype := dns.TypeToString[h.Rrtype]
+		switch h.Class {
+		case dns.ClassANY:
+			if h.Rdlength != 0 {
+				return dns.RcodeFormatError
+			}
+			exists, err := u.exists(name, h.Rrtype, rrtype)
+			if err != nil {
+				return dns.RcodeServerFailure
+			}
+			if !exists {
+				if h.Rrtype == dns.TypeANY {
+					return dns.RcodeNameError
+				}
+				return dns.RcodeNXRrset
+			}
+		case dns.ClassNONE:
+			if h.Rdlength != 0 {
+				return dns.RcodeFormatError
+			}
+			exists, err := u.exists(name, h.Rrtype, rrtype)
+			if err != nil {
+				return dns.RcodeServerFailure
+			}
+			if exists {
+				if h.Rrtype == dns.TypeANY {
+					return dns.RcodeYXDomain
+				}
+				return dns.RcodeYXRrset
+			}
+		case dns.ClassINET:
+			key := [2]string{name, rrtype}
+			if _, ok := expected[key]; !ok {
+				order = append(order, key)
+			}
+			expected[key] = append(expected[key], rr)
+		default:
+			return dns.RcodeFormatError
+		}
+	}
+	for _, key := range order {
+		_, current, err := u.rrset(key[0], key[1])
+		if err != nil {
+			return dns.RcodeServerFailure
+		}
+		if !sameRRs(current, expected[key]) {
+			return dns.RcodeNXRrset
+		}
+	}
+	return dns.RcodeSuccess
+}
+
+func (u *update) exists(name string, qtype uint16, rrtype string) (bool, error) {
+	if qtype == dns.TypeANY {
+		records, err := u.api.GetDomainRecordsByName(u.domain, name)
+		if isNotFound(err) {
+			return false, nil
+		}
+		return len(records) > 0, err
+	}
+	_, rrs, err := u.rrset(name, rrtype)
+	return len(rrs) > 0, err
+}
+
+func (u *update) checkUpdates(updates []dns.RR) int {
+	for _, rr := range updates {
+		h := rr.Header()
+		if _, ok := u.relative(h.Name); !ok {
+			return dns.RcodeNotZone
+		}
+		switch h.Class {
+		case dns.ClassINET:
+			if h.Rrtype == dns.TypeANY || h.Rrtype == dns.TypeAXFR || h.Rrtype == dns.TypeIXFR {
+				return dns.RcodeFormatError
+			}
+		case dns.ClassANY:
+			if h.Ttl != 0 || h.Rdlength != 0 {
+				return dns.RcodeFormatError
+			}
+		case dns.ClassNONE:
+			if h.Ttl != 0 || h.Rrtype == dns.TypeANY {
+				return dns.RcodeFormatError
+			}
+		default:
+			return dns.RcodeFormatError
+		}
+	}
+	return dns.RcodeSuccess
+}
+
+func (u *update) apply(rr dns.RR) error {
+	h := rr.Header()
+	name, _ := u.relative(h.Name)
+	rrtype := dns.TypeToString[h.Rrtype]
+	if h.Rrtype == dns.TypeSOA || (name == "@" && h.Rrtype == dns.TypeNS && h.Class != dns.ClassINET) {
+		return nil
+	}
+	switch h.Class {
+	case dns.ClassINET:
+		// The TTL of the update applies to the whole rrset
+		_, err := u.api.AddRecordValuesWithTTL(u.domain, name, rrtype, int(h.Ttl), []string{value(rr)})
+		return err
+	case dns.ClassANY:
+		if h.Rrtype != dns.TypeANY {
+			return u.deleteRrset(name, rrtype)
+		}
+		records, err := u.api.GetDomainRecordsByName(u.domain, name)
+		if isNotFound(err) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		for _, record := range records {
+			if name == "@" && (record.RrsetType == "NS" || record.RrsetType == "SOA") {
+				continue
+			}
+			if err := u.deleteRrset(name, record.RrsetType); err != nil {
+				return err
+			}
+		}
+		return nil
+	default:
+		// Delete the values of the rrset equal to the record,
+		// using their LiveDNS representation
+		record, current, err := u.rrset(name, rrtype)
+		if err != nil {
+			return err
+		}
+		target := dns.Copy(rr)
+		target.Header().Class = dns.ClassINET
+		var values []string
+		for i, existing := range current {
+			if dns.IsDuplicate(existing, target) {
+				values = append(values, record.RrsetValues[i])
+			}
+		}
+		if len(values) == 0 {
+			return nil
+		}
+		_, err = u.api.RemoveRecordValues(u.domain, name, rrtype, values)
+		return err
+	}
+}
+
+func (u *update) deleteRrset(name, rrtype string) error {
+	err := u.api.DeleteDomainRecord(u.domain, name, rrtype)
+	if isNotFound(err) {
+		return nil
+	}
+	return err
+}
+
+// sameRRs compares two sets of records regardless of their TTL
+func sameRRs(a, b []dns.RR) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for _, x := range a {
+		found := false
+		for _, y := range b {
+			if dns.IsDuplicate(x, y) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
+
+func isNotFound(err error) bool {
+	var e *types.RequestError
+	return errors.As(err, &e) && e.StatusCode == http.StatusNotFound
+}
@@ -0,0 +1,236 @@
+package rfc2136_test
+
+import (
+	"net"
+	"net/http"
+	"sort"
+	"sync"
+	"testing"
+
+	"github.com/go-gandi/go-gandi/livedns"
+	"github.com/go-gandi/go-gandi/livedns/rfc2136"
+	"github.com/go-gandi/go-gandi/types"
+	"github.com/miekg/dns"
+)
+
+// fakeAPI stores rrsets in memory, keyed by name and type
+type fakeAPI struct {
+	mu      sync.Mutex
+	records map[[2]string]livedns.DomainRecord
+}
+
+func notFound() error {
+	return &types.RequestError{StatusCode: http.StatusNotFound}
+}
+
+func (f *fakeAPI) GetDomainRecordsByName(fqdn, name string) ([]livedns.DomainRecord, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	var records []livedns.DomainRecord
+	for key, record := range f.records {
+		if key[0] == name {
+			records = append(records, record)
+		}
+	}
+	return records, nil
+}
+
+func (f *fakeAPI) GetDomainRecordByNameAndType(fqdn, name, recordtype string) (livedns.DomainRecord, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	record, ok := f.records[[2]string{name, recordtype}]
+	if !ok {
+		return record, notFound()
+	}
+	return record, nil
+}
+
+func (f *fakeAPI) AddRecordValuesWithTTL(fqdn, name, recordtype string, ttl int, values []string) (livedns.DomainRecord, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	key := [2]string{name, recordtype}
+	record, ok := f.records[key]
+	if !ok {
+		record = livedns.DomainRecord{RrsetName: name, RrsetType: recordtype}
+	}
+	record.RrsetTTL = ttl
+	record.RrsetValues = append(record.RrsetValues, values...)
+	f.records[key] = record
+	return record, nil
+}
+
+func (f *fakeAPI) RemoveRecordValues(fqdn, name, recordtype string, values []string) (livedns.DomainRecord, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	key := [2]string{name, recordtype}
+	record := f.records[key]
+	var kept []string
+	for _, existing := range record.RrsetValues {
+		removed := false
+		for _, value := range values {
+			removed = removed || existing == value
+		}
+		if !removed {
+			kept = append(kept, existing)
+		}
+	}
+	record.RrsetValues = kept
+	if len(kept) == 0 {
+		delete(f.records, key)
+	} else {
+		f.records[key] = record
+	}
+	return record, nil
+}
+
+func (f *fakeAPI) DeleteDomainRecord(fqdn, name, recordtype string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	key := [2]string{name, recordtype}
+	if _, ok := f.records[key]; !ok {
+		return notFound()
+	}
+	delete(f.records, key)
+	return nil
+}
+
+func (f *fakeAPI) values(name, recordtype string) []string {
+	record, _ := f.GetDomainRecordByNameAndType("", name, recordtype)
+	values := append([]string{}, record.RrsetValues...)
+	sort.Strings(values)
+	return values
+}
+
+const (
+	keyName = "update-key."
+	secret  = "c2VjcmV0c2VjcmV0c2VjcmV0c2VjcmV0"
+)
+
+func startGateway(t *testing.T, api *fakeAPI) string {
+	gateway := rfc2136.NewGateway(api, map[string]string{"example.com": "example.com"}, map[string]string{keyName: secret})
+	pc, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	server := gateway.Server("udp", "")
+	server.PacketConn = pc
+	started := make(chan struct{})
+	server.NotifyStartedFunc = func() { close(started) }
+	go func() { _ = server.ActivateAndServe() }()
+	<-started
+	t.Cleanup(func() { _ = server.Shutdown() })
+	return pc.LocalAddr().String()
+}
+
+func send(t *testing.T, addr string, m *dns.Msg, signed bool) int {
+	client := &dns.Client{TsigSecret: map[string]string{keyName: secret}}
+	if signed {
+		m.SetTsig(keyName, dns.HmacSHA256, 300, 0)
+	}
+	r, _, err := client.Exchange(m, addr)
+	// miekg/dns reports signed NOTAUTH responses as an
+	// authentication error
+	if err == dns.ErrAuth && r != nil && r.Rcode == dns.RcodeNotAuth {
+		return r.Rcode
+	}
+	if err != nil {
+		t.Fatal(err)
+	}
+	return r.Rcode
+}
+
+func rr(t *testing.T, s string) dns.RR {
+	r, err := dns.NewRR(s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return r
+}
+
+func TestUpdate(t *testing.T) {
+	api := &fakeAPI{records: map[[2]string]livedns.DomainRecord{
+		{"@", "NS"}:   {RrsetName: "@", RrsetType: "NS", RrsetTTL: 10800, RrsetValues: []string{"ns1.gandi.net."}},
+		{"www", "A"}:  {RrsetName: "www", RrsetType: "A", RrsetTTL: 300, RrsetValues: []string{"192.0.2.1"}},
+		{"old", "A"}:  {RrsetName: "old", RrsetType: "A", RrsetTTL: 300, RrsetValues: []string{"192.0.2.9"}},
+		{"old", "MX"}: {RrsetName: "old", RrsetType: "MX", RrsetTTL: 300, RrsetValues: []string{"10 mail"}},
+	}}
+	addr := startGateway(t, api)
+
+	m := new(dns.Msg)
+	m.SetUpdate("example.com.")
+	m.Insert([]dns.RR{
+		rr(t, "www.example.com. 600 IN A 192.0.2.2"),
+		rr(t, `_acme-challenge.example.com. 60 IN TXT "token"`),
+	})
+	m.Remove([]dns.RR{rr(t, "www.example.com. 0 IN A 192.0.2.1")})
+	m.RemoveName([]dns.RR{rr(t, "old.example.com. 0 IN ANY")})
+	m.RemoveRRset([]dns.RR{rr(t, "example.com. 0 IN NS")})
+	if rcode := send(t, addr, m, true); rcode != dns.RcodeSuccess {
+		t.Fatalf("Expected success, got %s", dns.RcodeToString[rcode])
+	}
+	if got := api.values("www", "A"); len(got) != 1 || got[0] != "192.0.2.2" {
+		t.Errorf("Unexpected www A values %v", got)
+	}
+	if record, _ := api.GetDomainRecordByNameAndType("", "www", "A"); record.RrsetTTL != 600 {
+		t.Errorf("Expected the TTL of the update, got %d", record.RrsetTTL)
+	}
+	if got := api.values("_acme-challenge", "TXT"); len(got) != 1 || got[0] != `"token"` {
+		t.Errorf("Unexpected TXT values %v", got)
+	}
+	if records, _ := api.GetDomainRecordsByName("", "old"); len(records) != 0 {
+		t.Errorf("Expected the records of 'old' to be deleted, got %v", records)
+	}
+	if got := api.values("@", "NS"); len(got) != 1 {
+		t.Errorf("Expected the apex NS to be kept, got %v", got)
+	}
+}
+
+func TestPrerequisites(t *testing.T) {
+	api := &fakeAPI{records: map[[2]string]livedns.DomainRecord{
+		{"mail", "MX"}: {RrsetName: "mail", RrsetType: "MX", RrsetTTL: 300, RrsetValues: []string{"10 mx"}},
+	}}
+	addr := startGateway(t, api)
+
+	cases := []struct {
+		name   string
+		prereq func(m *dns.Msg)
+		rcode  int
+	}{
+		{"name in use", func(m *dns.Msg) { m.NameUsed([]dns.RR{rr(t, "mail.example.com. 0 IN ANY")}) }, dns.RcodeSuccess},
+		{"name not in use", func(m *dns.Msg) { m.NameNotUsed([]dns.RR{rr(t, "mail.example.com. 0 IN ANY")}) }, dns.RcodeYXDomain},
+		{"rrset exists", func(m *dns.Msg) { m.RRsetUsed([]dns.RR{rr(t, "mail.example.com. 0 IN A")}) }, dns.RcodeNXRrset},
+		{"rrset does not exist", func(m *dns.Msg) { m.RRsetNotUsed([]dns.RR{rr(t, "mail.example.com. 0 IN MX")}) }, dns.RcodeYXRrset},
+		{"rrset values", func(m *dns.Msg) { m.Used([]dns.RR{rr(t, "mail.example.com. 0 IN MX 10 mx.example.com.")}) }, dns.RcodeSuccess},
+		{"wrong values", func(m *dns.Msg) { m.Used([]dns.RR{rr(t, "mail.example.com. 0 IN MX 20 mx.example.com.")}) }, dns.RcodeNXRrset},
+	}
+	for _, c := range cases {
+		m := new(dns.Msg)
+		m.SetUpdate("example.com.")
+		c.prereq(m)
+		if rcode := send(t, addr, m, true); rcode != c.rcode {
+			t.Errorf("%s: expected %s, got %s", c.name, dns.RcodeToString[c.rcode], dns.RcodeToString[rcode])
+		}
+	}
+}
+
+func TestRefused(t *testing.T) {
+	api := &fakeAPI{records: map[[2]string]livedns.DomainRecord{}}
+	addr := startGateway(t, api)
+
+	m := new(dns.Msg)
+	m.SetUpdate("example.com.")
+	m.Insert([]dns.RR{rr(t, "www.example.com. 300 IN A 192.0.2.2")})
+	if rcode := send(t, addr, m, false); rcode != dns.RcodeRefused {
+		t.Errorf("Expected an unsigned update to be refused, got %s", dns.RcodeToString[rcode])
+	}
+
+	m = new(dns.Msg)
+	m.SetUpdate("example.org.")
+	m.Insert([]dns.RR{rr(t, "www.example.org. 300 IN A 192.0.2.2")})
+	if rcode := send(t, addr, m, true); rcode != dns.RcodeNotAuth {
+		t.Errorf("Expected an update of an unknown zone to be refused, got %s", dns.RcodeToString[rcode])
+	}
+	if len(api.records) != 0 {
+		t.Errorf("Expected no record to be created, got %v", api.records)
+	}
+}
@@ -0,0 +1,142 @@
+package livedns
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// SecondaryFormat is the nameserver software targeted by a secondary
+// configuration
+type SecondaryFormat string
+
+const (
+	// SecondaryBIND generates a BIND named.conf snippet
+	SecondaryBIND SecondaryFormat = "bind"
+	// SecondaryKnot generates a Knot DNS knot.conf snippet
+	SecondaryKnot SecondaryFormat = "knot"
+	// SecondaryNSD generates a NSD nsd.conf snippet
+	SecondaryNSD SecondaryFormat = "nsd"
+	// SecondaryPowerDNS generates pdnsutil commands
+	SecondaryPowerDNS SecondaryFormat = "powerdns"
+)
+
+func (f SecondaryFormat) valid() bool {
+	switch f {
+	case SecondaryBIND, SecondaryKnot, SecondaryNSD, SecondaryPowerDNS:
+		return true
+	}
+	return false
+}
+
+// DefaultTSIGAlgorithm is the algorithm of the TSIG keys generated by
+// LiveDNS
+const DefaultTSIGAlgorithm = "hmac-sha512"
+
+// SecondaryConfig describes the zones a secondary nameserver
+// transfers from the LiveDNS primaries
+type SecondaryConfig struct {
+	// Key is the TSIG key authenticating the transfers
+	Key TSIGKey
+	// Algorithm is the TSIG algorithm, DefaultTSIGAlgorithm if empty
+	Algorithm string
+	// Primaries are the addresses of the LiveDNS servers the zones
+	// are transferred from
+	Primaries []string
+	// Zones are the domains to transfer
+	Zones []string
+}
+
+// RenderSecondaryConfig generates locally the configuration of a
+// secondary nameserver for all the zones of the config at once, while
+// GetTSIGKeyConfig returns a sample for a single key.
+func RenderSecondaryConfig(format SecondaryFormat, config SecondaryConfig) ([]byte, error) {
+	if config.Key.KeyName == "" || config.Key.Secret == "" {
+		return nil, fmt.Errorf("The TSIG key name and secret are required")
+	}
+	if len(config.Primaries) == 0 {
+		return nil, fmt.Errorf("At least one primary nameserver is required")
+	}
+	if config.Algorithm == "" {
+		config.Algorithm = DefaultTSIGAlgorithm
+	}
+	zones := make([]string, 0, len(config.Zones))
+	for _, zone := range config.Zones {
+		zones = append(zones, strings.TrimSuffix(zone, "."))
+	}
+	sort.Strings(zones)
+	config.Zones = zones
+
+	var b strings.Builder
+	switch format {
+	case SecondaryBIND:
+		renderBIND(&b, config)
+	case SecondaryKnot:
+		renderKnot(&b, config)
+	case SecondaryNSD:
+		renderNSD(&b, config)
+	case SecondaryPowerDNS:
+		renderPowerDNS(&b, config)
+	default:
+		return nil, fmt.Errorf("Unknown secondary configuration format '%s'", format)
+	}
+	return []byte(b.String()), nil
+}
+
+func renderBIND(b *strings.Builder, c SecondaryConfig) {
+	fmt.Fprintf(b, "key \"%s\" {\n\talgorithm %s;\n\tsecret \"%s\";\n};\n\n", c.Key.KeyName, c.Algorithm, c.Key.Secret)
+	for _, primary := range c.Primaries {
+		fmt.Fprintf(b, "server %s {\n\tkeys { \"%s\"; };\n};\n\n", primary, c.Key.KeyName)
+	}
+	for _, zone := range c.Zones {
+		fmt.Fprintf(b, "zone \"%s\" {\n\ttype secondary;\n\tfile \"secondary/%s.db\";\n\tprimaries {\n", zone, zone)
+		for _, primary := range c.Primaries {
+			fmt.Fprintf(b, "\t\t%s key \"%s\";\n", primary, c.Key.KeyName)
+		}
+		b.WriteString("\t};\n};\n\n")
+	}
+}
+
+func renderKnot(b *strings.Builder, c SecondaryConfig) {
+	fmt.Fprintf(b, "key:\n  - id: %s\n    algorithm: %s\n    secret: %s\n\n", c.Key.KeyName, c.Algorithm, c.Key.Secret)
+	addresses := strings.Join(c.Primaries, ", ")
+	fmt.Fprintf(b, "remote:\n  - id: gandi\n    address: [%s]\n    key: %s\n\n", addresses, c.Key.KeyName)
+	fmt.Fprintf(b, "acl:\n  - id: gandi_notify\n    address: [%s]\n    key: %s\n    action: notify\n\n", addresses, c.Key.KeyName)
+	if len(c.Zones) > 0 {
+		b.WriteString("zone:\n")
+	}
+	for _, zone := range c.Zones {
+		fmt.Fprintf(b, "  - domain: %s\n    master: gandi\n    acl: gandi_notify\n", zone)
+	}
+}
+
+func renderNSD(b *strings.Builder, c SecondaryConfig) {
+	fmt.Fprintf(b, "key:\n\tname: \"%s\"\n\talgorithm: %s\n\tsecret: \"%s\"\n\n", c.Key.KeyName, c.Algorithm, c.Key.Secret)
+	for _, zone := range c.Zones {
+		fmt.Fprintf(b, "zone:\n\tname: \"%s\"\n\tzonefile: \"%s.zone\"\n", zone, zone)
+		for _, primary := range c.Primaries {
+			fmt.Fprintf(b, "\tallow-notify: %s %s\n\trequest-xfr: %s %s\n", primary, c.Key.KeyName, primary, c.Key.KeyName)
+		}
+		b.WriteString("\n")
+	}
+}
+
+func renderPowerDNS(b *strings.Builder, c SecondaryConfig) {
+	fmt.Fprintf(b, "pdnsutil import-tsig-key %s %s %s\n", c.Key.KeyName, c.Algorithm, c.Key.Secret)
+	for _, zone := range c.Zones {
+		fmt.Fprintf(b, "pdnsutil create-secondary-zone %s %s\n", zone, strings.Join(c.Primaries, " "))
+		fmt.Fprintf(b, "pdnsutil set-meta %s AXFR-MASTER-TSIG %s\n", zone, c.Key.KeyName)
+	}
+}
+
+// GenerateSecondaryConfig fetches a TSIG key and renders the
+// configuration of a secondary nameserver transferring the given
+// zones with it. The key must be associated with each zone, see
+// AssociateTSIGKeyWithDomain.
+func (g *LiveDNS) GenerateSecondaryConfig(format SecondaryFormat, keyID string, primaries, zones []string) ([]byte, error) {
+	key, err := g.GetTSIGKey(keyID)
+	if err != nil {
+		return nil, err
+	}
+	return RenderSecondaryConfig(format, SecondaryConfig{Key: key, Primaries: primaries, Zones: zones})
+}
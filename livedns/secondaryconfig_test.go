@@ -0,0 +1,60 @@
+package livedns_test
+
+import (
+	"flag"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/go-gandi/go-gandi/livedns"
+)
+
+var update = flag.Bool("update", false, "update the golden files")
+
+func TestRenderSecondaryConfig(t *testing.T) {
+	config := livedns.SecondaryConfig{
+		Key:       livedns.TSIGKey{KeyName: "gandi-key", Secret: "c2VjcmV0"},
+		Primaries: []string{"217.70.177.40", "2001:4b98:d:1::40"},
+		Zones:     []string{"example.org.", "example.com"},
+	}
+	for _, format := range []livedns.SecondaryFormat{
+		livedns.SecondaryBIND,
+		livedns.SecondaryKnot,
+		livedns.SecondaryNSD,
+		livedns.SecondaryPowerDNS,
+	} {
+		output, err := livedns.RenderSecondaryConfig(format, config)
+		if err != nil {
+			t.Fatal(err)
+		}
+		golden := filepath.Join("testdata", "secondary."+string(format)+".golden")
+		if *update {
+			if err := os.WriteFile(golden, output, 0644); err != nil {
+				t.Fatal(err)
+			}
+		}
+		expected, err := os.ReadFile(golden)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(output) != string(expected) {
+			t.Errorf("The %s configuration should be:\n%s\n(while it is)\n%s", format, expected, output)
+		}
+	}
+}
+
+func TestRenderSecondaryConfigErrors(t *testing.T) {
+	key := livedns.TSIGKey{KeyName: "gandi-key", Secret: "c2VjcmV0"}
+	for _, tc := range []struct {
+		format livedns.SecondaryFormat
+		config livedns.SecondaryConfig
+	}{
+		{livedns.SecondaryBIND, livedns.SecondaryConfig{Primaries: []string{"192.0.2.1"}}},
+		{livedns.SecondaryBIND, livedns.SecondaryConfig{Key: key}},
+		{"djbdns", livedns.SecondaryConfig{Key: key, Primaries: []string{"192.0.2.1"}}},
+	} {
+		if _, err := livedns.RenderSecondaryConfig(tc.format, tc.config); err == nil {
+			t.Errorf("The %s configuration %+v should be rejected", tc.format, tc.config)
+		}
+	}
+}
@@ -2,6 +2,9 @@ package livedns
 
 import (
 	"encoding/json"
+	"fmt"
+	"sort"
+	"time"
 
 	"github.com/go-gandi/go-gandi/types"
 )
@@ -29,6 +32,13 @@ func (g *LiveDNS) CreateSnapshot(fqdn string) (response types.StandardResponse,
 	return
 }
 
+// CreateNamedSnapshot creates a snapshot for a domain with the given
+// name
+func (g *LiveDNS) CreateNamedSnapshot(fqdn, name string) (response types.StandardResponse, err error) {
+	_, err = g.client.Post("domains/"+fqdn+"/snapshots", createSnapshotRequest{Name: name}, &response)
+	return
+}
+
 // GetSnapshot returns a snapshot for a domain
 func (g *LiveDNS) GetSnapshot(fqdn, snapUUID string) (snapshot Snapshot, err error) {
 	_, err = g.client.Get("domains/"+fqdn+"/snapshots/"+snapUUID, nil, &snapshot)
@@ -40,3 +50,109 @@ func (g *LiveDNS) DeleteSnapshot(fqdn, snapUUID string) (err error) {
 	_, err = g.client.Delete("domains/"+fqdn+"/snapshots/"+snapUUID, nil, nil)
 	return
 }
+
+// RestoreSnapshot replaces the records of the zone attached to a
+// domain by the records stored in a snapshot
+func (g *LiveDNS) RestoreSnapshot(fqdn, snapUUID string) (response types.StandardResponse, err error) {
+	snapshot, err := g.GetSnapshot(fqdn, snapUUID)
+	if err != nil {
+		return
+	}
+	return g.UpdateDomainRecords(fqdn, snapshotRecords(snapshot))
+}
+
+// DiffSnapshots returns the changes between two snapshots of a
+// domain, from the snapshot fromUUID to the snapshot toUUID
+func (g *LiveDNS) DiffSnapshots(fqdn, fromUUID, toUUID string) ([]Change, error) {
+	from, err := g.GetSnapshot(fqdn, fromUUID)
+	if err != nil {
+		return nil, err
+	}
+	to, err := g.GetSnapshot(fqdn, toUUID)
+	if err != nil {
+		return nil, err
+	}
+	return DiffRecords(snapshotRecords(from), snapshotRecords(to)), nil
+}
+
+// DiffSnapshotWithZone returns the changes between the current records
+// of a domain and a snapshot, that is to say the changes which would
+// be applied by RestoreSnapshot
+func (g *LiveDNS) DiffSnapshotWithZone(fqdn, snapUUID string) ([]Change, error) {
+	snapshot, err := g.GetSnapshot(fqdn, snapUUID)
+	if err != nil {
+		return nil, err
+	}
+	current, err := g.GetDomainRecords(fqdn)
+	if err != nil {
+		return nil, err
+	}
+	return DiffRecords(current, snapshotRecords(snapshot)), nil
+}
+
+// snapshotRecords returns the records of a snapshot without their
+// href, which is not part of the zone content
+func snapshotRecords(snapshot Snapshot) []DomainRecord {
+	records := make([]DomainRecord, 0, len(snapshot.ZoneData))
+	for _, record := range snapshot.ZoneData {
+		record.RrsetHref = ""
+		records = append(records, record)
+	}
+	return records
+}
+
+// SnapshotsToPrune returns the snapshots which are not retained by the
+// policy, from the oldest to the most recent. A snapshot is retained
+// if it is one of the KeepLast most recent ones or if it has been
+// created less than KeepWithin before now. Automatic snapshots are
+// always retained unless the policy includes them.
+func SnapshotsToPrune(snapshots []Snapshot, policy RetentionPolicy, now time.Time) []Snapshot {
+	var candidates []Snapshot
+	for _, snapshot := range snapshots {
+		if snapshot.Automatic != nil && *snapshot.Automatic && !policy.IncludeAutomatic {
+			continue
+		}
+		candidates = append(candidates, snapshot)
+	}
+	sort.SliceStable(candidates, func(i, j int) bool {
+		return candidates[i].CreatedAt.After(candidates[j].CreatedAt)
+	})
+	var pruned []Snapshot
+	for i, snapshot := range candidates {
+		if i < policy.KeepLast {
+			continue
+		}
+		if policy.KeepWithin > 0 && now.Sub(snapshot.CreatedAt) < policy.KeepWithin {
+			continue
+		}
+		pruned = append(pruned, snapshot)
+	}
+	for i, j := 0, len(pruned)-1; i < j; i, j = i+1, j-1 {
+		pruned[i], pruned[j] = pruned[j], pruned[i]
+	}
+	return pruned
+}
+
+// PruneSnapshots deletes the snapshots of a domain which are not
+// retained by the policy, and returns them. When the client is
+// configured in dry run mode, nothing is deleted: the returned
+// snapshots are the ones which would be.
+func (g *LiveDNS) PruneSnapshots(fqdn string, policy RetentionPolicy) ([]Snapshot, error) {
+	if policy.KeepLast <= 0 && policy.KeepWithin <= 0 {
+		return nil, fmt.Errorf("The retention policy must keep at least one snapshot or a duration")
+	}
+	snapshots, err := g.ListSnapshots(fqdn)
+	if err != nil {
+		return nil, err
+	}
+	pruned := SnapshotsToPrune(snapshots, policy, time.Now())
+	if g.client.DryRun() {
+		return pruned, nil
+	}
+	for i, snapshot := range pruned {
+		if err := g.DeleteSnapshot(fqdn, snapshot.ID); err != nil {
+			return pruned[:i], fmt.Errorf("Fail to delete the snapshot '%s' (error '%w')", snapshot.ID, err)
+		}
+	}
+	return pruned, nil
+}
@@ -0,0 +1,128 @@
+package livedns_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/go-gandi/go-gandi/config"
+	"github.com/go-gandi/go-gandi/livedns"
+	"gopkg.in/h2non/gock.v1"
+)
+
+func TestSnapshotsToPrune(t *testing.T) {
+	now := time.Date(2021, 6, 1, 0, 0, 0, 0, time.UTC)
+	automatic := true
+	snapshots := []livedns.Snapshot{
+		{ID: "day-1", CreatedAt: now.Add(-24 * time.Hour)},
+		{ID: "day-10", CreatedAt: now.Add(-10 * 24 * time.Hour)},
+		{ID: "day-30", CreatedAt: now.Add(-30 * 24 * time.Hour)},
+		{ID: "day-20", CreatedAt: now.Add(-20 * 24 * time.Hour)},
+		{ID: "auto", CreatedAt: now.Add(-40 * 24 * time.Hour), Automatic: &automatic},
+	}
+	pruned := livedns.SnapshotsToPrune(snapshots, livedns.RetentionPolicy{KeepLast: 1, KeepWithin: 15 * 24 * time.Hour}, now)
+	if len(pruned) != 2 || pruned[0].ID != "day-30" || pruned[1].ID != "day-20" {
+		t.Fatalf("Snapshots day-30 and day-20 should be pruned (while pruned are %v)", pruned)
+	}
+	pruned = livedns.SnapshotsToPrune(snapshots, livedns.RetentionPolicy{KeepLast: 4, IncludeAutomatic: true}, now)
+	if len(pruned) != 1 || pruned[0].ID != "auto" {
+		t.Fatalf("The automatic snapshot should be pruned (while pruned are %v)", pruned)
+	}
+}
+
+func mockSnapshot(id string, records ...livedns.DomainRecord) {
+	gock.New("https://api.gandi.net/v5/").
+		Get("livedns/domains/example.com/snapshots/" + id).
+		Reply(200).
+		JSON(livedns.Snapshot{ID: id, ZoneData: records})
+}
+
+func TestRestoreSnapshot(t *testing.T) {
+	defer gock.Off()
+	// The CAA value is not accepted by the rdata validation, the
+	// snapshot is restored anyway
+	mockSnapshot("snap-id",
+		livedns.DomainRecord{RrsetName: "www", RrsetType: "A", RrsetTTL: 300, RrsetValues: []string{"192.0.2.1"}, RrsetHref: "https://api.gandi.net/v5/livedns/domains/example.com/records/www/A"},
+		livedns.DomainRecord{RrsetName: "@", RrsetType: "CAA", RrsetTTL: 300, RrsetValues: []string{`0 is-sue "letsencrypt.org"`}},
+	)
+	gock.New("https://api.gandi.net/v5/").
+		Put("livedns/domains/example.com/records").
+		JSON(map[string][]livedns.DomainRecord{"items": {
+			{RrsetName: "www", RrsetType: "A", RrsetTTL: 300, RrsetValues: []string{"192.0.2.1"}},
+			{RrsetName: "@", RrsetType: "CAA", RrsetTTL: 300, RrsetValues: []string{`0 is-sue "letsencrypt.org"`}},
+		}}).
+		Reply(201).
+		JSON(map[string]string{"message": "Zone updated"})
+
+	if _, err := livedns.New(config.Config{}).RestoreSnapshot("example.com", "snap-id"); err != nil {
+		t.Fatal(err)
+	}
+	if !gock.IsDone() {
+		t.Fatal("All the expected requests have not been sent")
+	}
+}
+
+func TestDiffSnapshots(t *testing.T) {
+	a1 := livedns.DomainRecord{RrsetName: "www", RrsetType: "A", RrsetTTL: 300, RrsetValues: []string{"192.0.2.1", "192.0.2.2"}}
+	a1Reordered := livedns.DomainRecord{RrsetName: "www", RrsetType: "A", RrsetTTL: 300, RrsetValues: []string{"192.0.2.2", "192.0.2.1"}}
+	a1TTL := livedns.DomainRecord{RrsetName: "www", RrsetType: "A", RrsetTTL: 3600, RrsetValues: []string{"192.0.2.1", "192.0.2.2"}}
+	a2 := livedns.DomainRecord{RrsetName: "www", RrsetType: "A", RrsetTTL: 300, RrsetValues: []string{"192.0.2.3"}}
+	mx := livedns.DomainRecord{RrsetName: "@", RrsetType: "MX", RrsetTTL: 300, RrsetValues: []string{"10 spool.mail.gandi.net."}}
+	for _, tc := range []struct {
+		name     string
+		from, to []livedns.DomainRecord
+		expected []string
+	}{
+		{"identical", []livedns.DomainRecord{a1, mx}, []livedns.DomainRecord{mx, a1}, nil},
+		{"reordered values", []livedns.DomainRecord{a1}, []livedns.DomainRecord{a1Reordered}, nil},
+		{"created", []livedns.DomainRecord{a1}, []livedns.DomainRecord{a1, mx}, []string{"+ @ MX 300 [10 spool.mail.gandi.net.]"}},
+		{"deleted", []livedns.DomainRecord{a1, mx}, []livedns.DomainRecord{a1}, []string{"- @ MX 300 [10 spool.mail.gandi.net.]"}},
+		{"ttl", []livedns.DomainRecord{a1}, []livedns.DomainRecord{a1TTL}, []string{"~ www A ttl 300 -> 3600"}},
+		{"updated", []livedns.DomainRecord{a1, mx}, []livedns.DomainRecord{a2}, []string{
+			"- @ MX 300 [10 spool.mail.gandi.net.]",
+			"~ www A 300 [192.0.2.1, 192.0.2.2] -> 300 [192.0.2.3]",
+		}},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			defer gock.Off()
+			mockSnapshot("from", tc.from...)
+			mockSnapshot("to", tc.to...)
+			changes, err := livedns.New(config.Config{}).DiffSnapshots("example.com", "from", "to")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(changes) != len(tc.expected) {
+				t.Fatalf("Expected the changes %v (got %v)", tc.expected, changes)
+			}
+			for i, change := range changes {
+				if change.String() != tc.expected[i] {
+					t.Errorf("Expected the change '%s' (got '%s')", tc.expected[i], change)
+				}
+			}
+		})
+	}
+}
+
+func TestDiffSnapshotWithZone(t *testing.T) {
+	defer gock.Off()
+	mockSnapshot("snap-id", livedns.DomainRecord{RrsetName: "www", RrsetType: "A", RrsetTTL: 300, RrsetValues: []string{"192.0.2.1"}})
+	gock.New("https://api.gandi.net/v5/").
+		Get("livedns/domains/example.com/records").
+		Reply(200).
+		JSON([]livedns.DomainRecord{
+			{RrsetName: "www", RrsetType: "A", RrsetTTL: 300, RrsetValues: []string{"192.0.2.2"}},
+			{RrsetName: "new", RrsetType: "A", RrsetTTL: 300, RrsetValues: []string{"192.0.2.3"}},
+		})
+
+	// The changes are those restoring the snapshot would apply
+	changes, err := livedns.New(config.Config{}).DiffSnapshotWithZone("example.com", "snap-id")
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := []string{"- new A 300 [192.0.2.3]", "~ www A 300 [192.0.2.2] -> 300 [192.0.2.1]"}
+	if len(changes) != len(expected) || changes[0].String() != expected[0] || changes[1].String() != expected[1] {
+		t.Fatalf("Expected the changes %v (got %v)", expected, changes)
+	}
+	if !gock.IsDone() {
+		t.Fatal("All the expected requests have not been sent")
+	}
+}
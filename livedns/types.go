@@ -21,7 +21,8 @@ type Domain struct {
 }
 
 type zone struct {
-	TTL int `json:"ttl"`
+	TTL   int            `json:"ttl"`
+	Items []DomainRecord `json:"items,omitempty"`
 }
 
 type createDomainRequest struct {
@@ -29,7 +30,14 @@ type createDomainRequest struct {
 	Zone zone   `json:"zone,omitempty"`
 }
 
+// DNSSECAvailability tells if DNSSEC can be enabled on a domain
+type DNSSECAvailability struct {
+	Available bool `json:"available"`
+}
+
 // Tsig contains tsig data (no kidding!)
+//
+// Deprecated: use TSIGKey
 type Tsig struct {
 	KeyName       string      `json:"key_name,omitempty"`
 	Secret        string      `json:"secret,omitempty"`
@@ -47,6 +55,17 @@ type DomainRecord struct {
 	RrsetValues []string `json:"rrset_values,omitempty"`
 }
 
+// ImportMode defines how imported records are combined with the
+// records already present in a zone
+type ImportMode int
+
+const (
+	// ImportReplace replaces all the records of the zone
+	ImportReplace ImportMode = iota
+	// ImportMerge adds the records to the ones already in the zone
+	ImportMerge
+)
+
 // SigningKey holds data about a DNSSEC signing key
 type SigningKey struct {
 	Status        string `json:"status,omitempty"`
@@ -62,7 +81,9 @@ type SigningKey struct {
 	KeyHref       string `json:"key_href,omitempty"`
 }
 
-type configSamples struct {
+// TSIGConfigSamples contains the sample nameserver configurations
+// returned by the API along with a TSIG key
+type TSIGConfigSamples struct {
 	Bind     string `json:"bind,omitempty"`
 	Knot     string `json:"knot,omitempty"`
 	NSD      string `json:"nsd,omitempty"`
@@ -71,11 +92,11 @@ type configSamples struct {
 
 // TSIGKey describes the TSIG key associated with an AXFR secondary
 type TSIGKey struct {
-	KeyHREF       string        `json:"href,omitempty"`
-	ID            string        `json:"id,omitempty"`
-	KeyName       string        `json:"key_name,omitempty"`
-	Secret        string        `json:"secret,omitempty"`
-	ConfigSamples configSamples `json:"config_samples,omitempty"`
+	KeyHREF       string            `json:"href,omitempty"`
+	ID            string            `json:"id,omitempty"`
+	KeyName       string            `json:"key_name,omitempty"`
+	Secret        string            `json:"secret,omitempty"`
+	ConfigSamples TSIGConfigSamples `json:"config_samples,omitempty"`
 }
 
 // Snapshot represents a point in time record of a domain
@@ -88,6 +109,21 @@ type Snapshot struct {
 	ZoneData     []DomainRecord `json:"zone_data,omitempty"`
 }
 
+type createSnapshotRequest struct {
+	Name string `json:"name,omitempty"`
+}
+
+// RetentionPolicy defines which snapshots are kept by PruneSnapshots
+type RetentionPolicy struct {
+	// KeepLast is the number of most recent snapshots to keep
+	KeepLast int
+	// KeepWithin keeps the snapshots created during this duration
+	KeepWithin time.Duration
+	// IncludeAutomatic makes automatic snapshots subject to the
+	// policy. They are always kept otherwise.
+	IncludeAutomatic bool
+}
+
 // UpdateDomainRequest contains the params for the UpdateDomain method
 type UpdateDomainRequest struct {
 	AutomaticSnapshots *bool `json:"automatic_snapshots,omitempty"`
@@ -0,0 +1,171 @@
+package livedns
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/go-gandi/go-gandi/internal/retry"
+	"github.com/go-gandi/go-gandi/types"
+)
+
+// ErrConflict is returned when a rrset is still concurrently modified
+// after all the attempts of AddRecordValues or RemoveRecordValues
+var ErrConflict = errors.New("The rrset has been concurrently modified")
+
+// AddRecordValues adds values to the rrset with the given name and
+// type, creating it with the given TTL if it does not exist. The TTL
+// of an existing rrset is left unchanged. Values already present are
+// ignored.
+//
+// The rrset is read, modified and written back, then read again to
+// check it holds exactly the written values: if another writer
+// replaced the rrset in the meantime, even keeping our values, the
+// whole cycle is retried from the new content. This narrows the race
+// but does not close it, since LiveDNS has no conditional write: a
+// concurrent write landing between our read and our write is
+// overwritten, and a write landing after our check may drop our
+// values, without being detected unless the other writer checks its
+// own result the same way. Writers of the same rrset within a process
+// should be serialized. The returned record is the rrset as written.
+func (g *LiveDNS) AddRecordValues(fqdn, name, recordtype string, ttl int, values []string) (DomainRecord, error) {
+	return g.addRecordValues(fqdn, name, recordtype, ttl, false, values)
+}
+
+// AddRecordValuesWithTTL adds values as AddRecordValues does, but also
+// sets the TTL of an existing rrset, as a dynamic update (RFC 2136)
+// does.
+func (g *LiveDNS) AddRecordValuesWithTTL(fqdn, name, recordtype string, ttl int, values []string) (DomainRecord, error) {
+	return g.addRecordValues(fqdn, name, recordtype, ttl, true, values)
+}
+
+func (g *LiveDNS) addRecordValues(fqdn, name, recordtype string, ttl int, setTTL bool, values []string) (DomainRecord, error) {
+	return g.modifyRecordValues(fqdn, name, recordtype, ttl, setTTL, func(current []string) []string {
+		return unionValues(current, values)
+	}, func(current []string) bool {
+		return containsValues(current, values)
+	})
+}
+
+// RemoveRecordValues removes values from the rrset with the given name
+// and type. The rrset is deleted when no value remains, and nothing
+// is done if it does not exist. Concurrent modifications are handled
+// as in AddRecordValues.
+func (g *LiveDNS) RemoveRecordValues(fqdn, name, recordtype string, values []string) (DomainRecord, error) {
+	return g.modifyRecordValues(fqdn, name, recordtype, 0, false, func(current []string) []string {
+		return subtractValues(current, values)
+	}, func(current []string) bool {
+		for _, value := range values {
+			if containsValues(current, []string{value}) {
+				return false
+			}
+		}
+		return true
+	})
+}
+
+// modifyRecordValues writes the modified values of the rrset. The TTL
+// of an existing rrset is replaced by ttl when setTTL is set.
+func (g *LiveDNS) modifyRecordValues(fqdn, name, recordtype string, ttl int, setTTL bool, modify func([]string) []string, done func([]string) bool) (DomainRecord, error) {
+	var result DomainRecord
+	ok, err := retry.Do(func() (bool, error) {
+		current, exists, err := g.getRrset(fqdn, name, recordtype)
+		if err != nil {
+			return false, err
+		}
+		if done(current.RrsetValues) && (!setTTL || !exists || current.RrsetTTL == ttl) {
+			result = current
+			return true, nil
+		}
+		desired := DomainRecord{
+			RrsetName:   name,
+			RrsetType:   recordtype,
+			RrsetTTL:    current.RrsetTTL,
+			RrsetValues: modify(current.RrsetValues),
+		}
+		if setTTL {
+			desired.RrsetTTL = ttl
+		}
+		switch {
+		case !exists:
+			desired.RrsetTTL = ttl
+			_, err = g.CreateDomainRecord(fqdn, name, recordtype, ttl, desired.RrsetValues)
+		case len(desired.RrsetValues) == 0:
+			err = g.DeleteDomainRecord(fqdn, name, recordtype)
+		default:
+			_, err = g.UpdateDomainRecordByNameAndType(fqdn, name, recordtype, desired.RrsetTTL, desired.RrsetValues)
+		}
+		if isStatus(err, http.StatusConflict) || isStatus(err, http.StatusNotFound) {
+			// The rrset has been created or deleted since we
+			// read it
+			return false, nil
+		}
+		if err != nil {
+			return false, err
+		}
+		written, _, err := g.getRrset(fqdn, name, recordtype)
+		if err != nil {
+			return false, err
+		}
+		result = desired
+		return sameValues(written.RrsetValues, desired.RrsetValues), nil
+	})
+	if err != nil {
+		return DomainRecord{}, err
+	}
+	if !ok {
+		return DomainRecord{}, fmt.Errorf("%w: %s %s in %s", ErrConflict, name, recordtype, fqdn)
+	}
+	return result, nil
+}
+
+// getRrset returns the rrset and false if it does not exist
+func (g *LiveDNS) getRrset(fqdn, name, recordtype string) (DomainRecord, bool, error) {
+	record, err := g.GetDomainRecordByNameAndType(fqdn, name, recordtype)
+	if isStatus(err, http.StatusNotFound) {
+		return DomainRecord{}, false, nil
+	}
+	if err != nil {
+		return DomainRecord{}, false, err
+	}
+	return record, true, nil
+}
+
+func isStatus(err error, status int) bool {
+	var e *types.RequestError
+	return errors.As(err, &e) && e.StatusCode == status
+}
+
+func unionValues(current, values []string) []string {
+	result := append([]string(nil), current...)
+	for _, value := range values {
+		if !containsValues(result, []string{value}) {
+			result = append(result, value)
+		}
+	}
+	return result
+}
+
+func subtractValues(current, values []string) []string {
+	var result []string
+	for _, value := range current {
+		if !containsValues(values, []string{value}) {
+			result = append(result, value)
+		}
+	}
+	return result
+}
+
+// containsValues returns true if all the values are in the set
+func containsValues(set, values []string) bool {
+values:
+	for _, value := range values {
+		for _, v := range set {
+			if v == value {
+				continue values
+			}
+		}
+		return false
+	}
+	return true
+}
@@ -0,0 +1,169 @@
+package livedns_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/go-gandi/go-gandi/config"
+	"github.com/go-gandi/go-gandi/livedns"
+	"gopkg.in/h2non/gock.v1"
+)
+
+func TestAddRecordValuesCreate(t *testing.T) {
+	defer gock.Off()
+	gock.New("https://api.gandi.net/v5/").
+		Get("livedns/domains/example.com/records/www/A").
+		Reply(404).
+		JSON(map[string]interface{}{"code": 404, "message": "Unknown record"})
+	gock.New("https://api.gandi.net/v5/").
+		Post("livedns/domains/example.com/records").
+		JSON(livedns.DomainRecord{RrsetName: "www", RrsetType: "A", RrsetTTL: 300, RrsetValues: []string{"192.0.2.1"}}).
+		Reply(201).
+		JSON(map[string]string{"message": "DNS Record Created"})
+	gock.New("https://api.gandi.net/v5/").
+		Get("livedns/domains/example.com/records/www/A").
+		Reply(200).
+		JSON(livedns.DomainRecord{RrsetName: "www", RrsetType: "A", RrsetTTL: 300, RrsetValues: []string{"192.0.2.1"}})
+
+	liveDNS := livedns.New(config.Config{})
+	record, err := liveDNS.AddRecordValues("example.com", "www", "A", 300, []string{"192.0.2.1"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(record.RrsetValues, []string{"192.0.2.1"}) {
+		t.Fatalf("Unexpected record %#v", record)
+	}
+	if !gock.IsDone() {
+		t.Fatal("All the expected requests have not been sent")
+	}
+}
+
+func TestRemoveRecordValuesDeletesEmptyRrset(t *testing.T) {
+	defer gock.Off()
+	gock.New("https://api.gandi.net/v5/").
+		Get("livedns/domains/example.com/records/www/A").
+		Reply(200).
+		JSON(livedns.DomainRecord{RrsetName: "www", RrsetType: "A", RrsetTTL: 300, RrsetValues: []string{"192.0.2.1"}})
+	gock.New("https://api.gandi.net/v5/").
+		Delete("livedns/domains/example.com/records/www/A").
+		Reply(204)
+	gock.New("https://api.gandi.net/v5/").
+		Get("livedns/domains/example.com/records/www/A").
+		Reply(404).
+		JSON(map[string]interface{}{"code": 404, "message": "Unknown record"})
+
+	liveDNS := livedns.New(config.Config{})
+	if _, err := liveDNS.RemoveRecordValues("example.com", "www", "A", []string{"192.0.2.1"}); err != nil {
+		t.Fatal(err)
+	}
+	if !gock.IsDone() {
+		t.Fatal("All the expected requests have not been sent")
+	}
+}
+
+func TestAddRecordValuesRetriesConcurrentWrite(t *testing.T) {
+	defer gock.Off()
+	gock.New("https://api.gandi.net/v5/").
+		Get("livedns/domains/example.com/records/_acme-challenge/TXT").
+		Reply(200).
+		JSON(livedns.DomainRecord{RrsetName: "_acme-challenge", RrsetType: "TXT", RrsetTTL: 300, RrsetValues: []string{`"x"`}})
+	gock.New("https://api.gandi.net/v5/").
+		Put("livedns/domains/example.com/records/_acme-challenge/TXT").
+		JSON(livedns.DomainRecord{RrsetType: "TXT", RrsetTTL: 300, RrsetValues: []string{`"x"`, `"a"`}}).
+		Reply(201).
+		JSON(map[string]string{"message": "DNS Record Created"})
+	// Another writer replaced the rrset with its own value, keeping
+	// the value of this one out
+	gock.New("https://api.gandi.net/v5/").
+		Get("livedns/domains/example.com/records/_acme-challenge/TXT").
+		Times(2).
+		Reply(200).
+		JSON(livedns.DomainRecord{RrsetName: "_acme-challenge", RrsetType: "TXT", RrsetTTL: 300, RrsetValues: []string{`"x"`, `"b"`}})
+	gock.New("https://api.gandi.net/v5/").
+		Put("livedns/domains/example.com/records/_acme-challenge/TXT").
+		JSON(livedns.DomainRecord{RrsetType: "TXT", RrsetTTL: 300, RrsetValues: []string{`"x"`, `"b"`, `"a"`}}).
+		Reply(201).
+		JSON(map[string]string{"message": "DNS Record Created"})
+	gock.New("https://api.gandi.net/v5/").
+		Get("livedns/domains/example.com/records/_acme-challenge/TXT").
+		Reply(200).
+		JSON(livedns.DomainRecord{RrsetName: "_acme-challenge", RrsetType: "TXT", RrsetTTL: 300, RrsetValues: []string{`"a"`, `"b"`, `"x"`}})
+
+	liveDNS := livedns.New(config.Config{})
+	record, err := liveDNS.AddRecordValues("example.com", "_acme-challenge", "TXT", 300, []string{`"a"`})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(record.RrsetValues, []string{`"x"`, `"b"`, `"a"`}) {
+		t.Fatalf("Unexpected record %#v", record)
+	}
+	if !gock.IsDone() {
+		t.Fatal("All the expected requests have not been sent")
+	}
+}
+
+func TestAddRecordValuesRetriesWhenOwnValueKept(t *testing.T) {
+	defer gock.Off()
+	gock.New("https://api.gandi.net/v5/").
+		Get("livedns/domains/example.com/records/www/A").
+		Reply(200).
+		JSON(livedns.DomainRecord{RrsetName: "www", RrsetType: "A", RrsetTTL: 300, RrsetValues: []string{"192.0.2.1"}})
+	gock.New("https://api.gandi.net/v5/").
+		Put("livedns/domains/example.com/records/www/A").
+		JSON(livedns.DomainRecord{RrsetType: "A", RrsetTTL: 300, RrsetValues: []string{"192.0.2.1", "192.0.2.2"}}).
+		Reply(201).
+		JSON(map[string]string{"message": "DNS Record Created"})
+	// The rrset differs from the written one although it holds our
+	// value: the concurrent write of 192.0.2.3 may have been lost, so
+	// the next cycle starts from the new content
+	gock.New("https://api.gandi.net/v5/").
+		Get("livedns/domains/example.com/records/www/A").
+		Reply(200).
+		JSON(livedns.DomainRecord{RrsetName: "www", RrsetType: "A", RrsetTTL: 300, RrsetValues: []string{"192.0.2.1", "192.0.2.2", "192.0.2.3"}})
+	gock.New("https://api.gandi.net/v5/").
+		Get("livedns/domains/example.com/records/www/A").
+		Reply(200).
+		JSON(livedns.DomainRecord{RrsetName: "www", RrsetType: "A", RrsetTTL: 300, RrsetValues: []string{"192.0.2.1", "192.0.2.2", "192.0.2.3"}})
+
+	liveDNS := livedns.New(config.Config{})
+	record, err := liveDNS.AddRecordValues("example.com", "www", "A", 300, []string{"192.0.2.2"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(record.RrsetValues) != 3 {
+		t.Fatalf("Unexpected record %#v", record)
+	}
+	if !gock.IsDone() {
+		t.Fatal("All the expected requests have not been sent")
+	}
+}
+
+func TestAddRecordValuesWithTTL(t *testing.T) {
+	defer gock.Off()
+	// The value is already there, with another TTL
+	gock.New("https://api.gandi.net/v5/").
+		Get("livedns/domains/example.com/records/www/A").
+		Reply(200).
+		JSON(livedns.DomainRecord{RrsetName: "www", RrsetType: "A", RrsetTTL: 300, RrsetValues: []string{"192.0.2.1"}})
+	gock.New("https://api.gandi.net/v5/").
+		Put("livedns/domains/example.com/records/www/A").
+		JSON(livedns.DomainRecord{RrsetType: "A", RrsetTTL: 600, RrsetValues: []string{"192.0.2.1"}}).
+		Reply(201).
+		JSON(map[string]string{"message": "DNS Record Created"})
+	gock.New("https://api.gandi.net/v5/").
+		Get("livedns/domains/example.com/records/www/A").
+		Reply(200).
+		JSON(livedns.DomainRecord{RrsetName: "www", RrsetType: "A", RrsetTTL: 600, RrsetValues: []string{"192.0.2.1"}})
+
+	liveDNS := livedns.New(config.Config{})
+	record, err := liveDNS.AddRecordValuesWithTTL("example.com", "www", "A", 600, []string{"192.0.2.1"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if record.RrsetTTL != 600 {
+		t.Fatalf("Unexpected record %#v", record)
+	}
+	if !gock.IsDone() {
+		t.Fatal("All the expected requests have not been sent")
+	}
+}
@@ -0,0 +1,451 @@
+// Package zonefile converts between RFC 1035 master files (BIND-style
+// zone files) and LiveDNS records.
+//
+// Records are grouped into rrsets: all the values sharing a name and a
+// type end up in a single livedns.DomainRecord. Names are stored
+// relative to the origin, the apex being "@", which is how LiveDNS
+// names its rrsets.
+package zonefile
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/go-gandi/go-gandi/livedns"
+)
+
+// DefaultTTL is the TTL used for records that have no explicit TTL
+// when the zone file does not contain a $TTL directive. It matches the
+// LiveDNS default.
+const DefaultTTL = 10800
+
+// Apex is the LiveDNS name of the zone apex
+const Apex = "@"
+
+// ParseError describes a syntax error in a zone file
+type ParseError struct {
+	Line int
+	Err  string
+}
+
+func (e *ParseError) Error() string {
+	return fmt.Sprintf("Zone file line %d: %s", e.Line, e.Err)
+}
+
+var classes = map[string]bool{"IN": true, "CH": true, "HS": true, "CS": true}
+
+// nameFields are the positions of the domain names in the data of the
+// record types. They are made absolute, since LiveDNS would resolve a
+// relative name against the zone apex instead of the current origin.
+var nameFields = map[string][]int{
+	"CNAME": {0},
+	"DNAME": {0},
+	"ALIAS": {0},
+	"NS":    {0},
+	"PTR":   {0},
+	"MX":    {1},
+	"SRV":   {3},
+	"SOA":   {0, 1},
+	"SVCB":  {1},
+	"HTTPS": {1},
+}
+
+// Parse reads a zone file and returns its records grouped into
+// rrsets. The origin is used to resolve "@" and relative names until
+// a $ORIGIN directive changes it. Names that are not inside the
+// origin given as argument are rejected since they cannot be stored in
+// the LiveDNS zone. The domain names in the data of the records, such
+// as CNAME targets or MX hosts, are made absolute against the current
+// origin as well.
+//
+// When the records of a rrset have different TTLs, the smallest one is
+// kept, as recommended by RFC 2181.
+func Parse(r io.Reader, origin string) ([]livedns.DomainRecord, error) {
+	zone := canonical(origin)
+	if zone == "." {
+		return nil, fmt.Errorf("The zone origin must not be empty")
+	}
+	p := parser{
+		zone:   zone,
+		origin: zone,
+		ttl:    -1,
+		index:  map[[2]string]int{},
+	}
+	if err := p.parse(r); err != nil {
+		return nil, err
+	}
+	return p.records, nil
+}
+
+type parser struct {
+	zone      string
+	origin    string
+	ttl       int
+	lastTTL   int
+	lastOwner string
+	records   []livedns.DomainRecord
+	index     map[[2]string]int
+}
+
+func (p *parser) parse(r io.Reader) error {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	var (
+		tokens    []string
+		depth     int
+		startLine int
+		indented  bool
+		lineNo    int
+	)
+	for scanner.Scan() {
+		lineNo++
+		line := scanner.Text()
+		if depth == 0 {
+			startLine = lineNo
+			indented = len(line) > 0 && (line[0] == ' ' || line[0] == '\t')
+		}
+		var err error
+		tokens, depth, err = tokenize(line, tokens, depth)
+		if err != nil {
+			return &ParseError{Line: lineNo, Err: err.Error()}
+		}
+		if depth > 0 || len(tokens) == 0 {
+			continue
+		}
+		if err := p.entry(tokens, indented); err != nil {
+			return &ParseError{Line: startLine, Err: err.Error()}
+		}
+		tokens = nil
+	}
+	if err := scanner.Err(); err != nil {
+		return fmt.Errorf("Fail to read the zone file (error '%w')", err)
+	}
+	if depth > 0 {
+		return &ParseError{Line: startLine, Err: "unbalanced parentheses"}
+	}
+	return nil
+}
+
+// tokenize splits a line into tokens, appending them to the ones
+// collected from the previous lines of a parenthesized entry. Quoted
+// strings are kept with their quotes, comments are dropped.
+func tokenize(line string, tokens []string, depth int) ([]string, int, error) {
+	var (
+		current  strings.Builder
+		inQuotes bool
+		escaped  bool
+		pending  bool
+	)
+	flush := func() {
+		if pending {
+			tokens = append(tokens, current.String())
+			current.Reset()
+			pending = false
+		}
+	}
+	for _, r := range line {
+		switch {
+		case escaped:
+			current.WriteRune(r)
+			escaped = false
+		case r == '\\':
+			current.WriteRune(r)
+			escaped = true
+			pending = true
+		case r == '"':
+			current.WriteRune(r)
+			inQuotes = !inQuotes
+			pending = true
+		case inQuotes:
+			current.WriteRune(r)
+		case r == ';':
+			flush()
+			return tokens, depth, nil
+		case r == '(':
+			flush()
+			depth++
+		case r == ')':
+			flush()
+			if depth == 0 {
+				return nil, 0, fmt.Errorf("unexpected ')'")
+			}
+			depth--
+		case unicode.IsSpace(r):
+			flush()
+		default:
+			current.WriteRune(r)
+			pending = true
+		}
+	}
+	if inQuotes {
+		return nil, 0, fmt.Errorf("unterminated quoted string")
+	}
+	flush()
+	return tokens, depth, nil
+}
+
+func (p *parser) entry(tokens []string, indented bool) error {
+	switch strings.ToUpper(tokens[0]) {
+	case "$ORIGIN":
+		if len(tokens) != 2 {
+			return fmt.Errorf("$ORIGIN expects a single domain name")
+		}
+		p.origin = p.absolute(tokens[1])
+		return nil
+	case "$TTL":
+		if len(tokens) != 2 {
+			return fmt.Errorf("$TTL expects a single value")
+		}
+		ttl, err := ParseTTL(tokens[1])
+		if err != nil {
+			return err
+		}
+		p.ttl = ttl
+		return nil
+	case "$INCLUDE", "$GENERATE":
+		return fmt.Errorf("%s is not supported", tokens[0])
+	}
+
+	owner := p.lastOwner
+	if !indented {
+		owner = p.absolute(tokens[0])
+		tokens = tokens[1:]
+	}
+	if owner == "" {
+		return fmt.Errorf("the record has no owner name")
+	}
+	p.lastOwner = owner
+
+	ttl := -1
+	for len(tokens) > 0 {
+		if classes[strings.ToUpper(tokens[0])] {
+			tokens = tokens[1:]
+			continue
+		}
+		if ttl < 0 && startsWithDigit(tokens[0]) {
+			v, err := ParseTTL(tokens[0])
+			if err != nil {
+				return err
+			}
+			ttl = v
+			tokens = tokens[1:]
+			continue
+		}
+		break
+	}
+	if len(tokens) == 0 {
+		return fmt.Errorf("the record has no type")
+	}
+	rrtype := strings.ToUpper(tokens[0])
+	rdata := append([]string(nil), tokens[1:]...)
+	if len(rdata) == 0 {
+		return fmt.Errorf("the %s record has no data", rrtype)
+	}
+	for _, i := range nameFields[rrtype] {
+		if i < len(rdata) {
+			rdata[i] = p.absolute(rdata[i])
+		}
+	}
+
+	switch {
+	case ttl >= 0:
+		p.lastTTL = ttl
+	case p.ttl >= 0:
+		ttl = p.ttl
+	case p.lastTTL > 0:
+		ttl = p.lastTTL
+	default:
+		ttl = DefaultTTL
+	}
+
+	name, err := p.relative(owner)
+	if err != nil {
+		return err
+	}
+	p.add(name, rrtype, ttl, strings.Join(rdata, " "))
+	return nil
+}
+
+func (p *parser) add(name, rrtype string, ttl int, value string) {
+	key := [2]string{name, rrtype}
+	i, ok := p.index[key]
+	if !ok {
+		p.index[key] = len(p.records)
+		p.records = append(p.records, livedns.DomainRecord{
+			RrsetName:   name,
+			RrsetType:   rrtype,
+			RrsetTTL:    ttl,
+			RrsetValues: []string{value},
+		})
+		return
+	}
+	record := &p.records[i]
+	if ttl < record.RrsetTTL {
+		record.RrsetTTL = ttl
+	}
+	for _, v := range record.RrsetValues {
+		if v == value {
+			return
+		}
+	}
+	record.RrsetValues = append(record.RrsetValues, value)
+}
+
+// absolute returns the fully qualified, lower-cased form of a name
+// found in the zone file.
+func (p *parser) absolute(name string) string {
+	if name == "@" {
+		return p.origin
+	}
+	if strings.HasSuffix(name, ".") {
+		return canonical(name)
+	}
+	if p.origin == "." {
+		return canonical(name)
+	}
+	return canonical(name + "." + p.origin)
+}
+
+// relative returns the LiveDNS rrset name of an absolute name
+func (p *parser) relative(name string) (string, error) {
+	if name == p.zone {
+		return Apex, nil
+	}
+	if strings.HasSuffix(name, "."+p.zone) {
+		return strings.TrimSuffix(name, "."+p.zone), nil
+	}
+	return "", fmt.Errorf("the name '%s' is outside of the zone '%s'", name, p.zone)
+}
+
+// canonical returns the lower-cased name with a trailing dot
+func canonical(name string) string {
+	name = strings.ToLower(strings.TrimSuffix(name, "."))
+	return name + "."
+}
+
+func startsWithDigit(s string) bool {
+	return s != "" && s[0] >= '0' && s[0] <= '9'
+}
+
+// ParseTTL parses a TTL expressed either as a number of seconds or
+// with BIND unit suffixes, such as "1h30m" or "2d".
+func ParseTTL(s string) (int, error) {
+	if v, err := strconv.Atoi(s); err == nil {
+		if v < 0 {
+			return 0, fmt.Errorf("invalid TTL '%s'", s)
+		}
+		return v, nil
+	}
+	units := map[byte]int{'s': 1, 'm': 60, 'h': 3600, 'd': 86400, 'w': 604800}
+	total, current, digits := 0, 0, 0
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if c >= '0' && c <= '9' {
+			current = current*10 + int(c-'0')
+			digits++
+			continue
+		}
+		unit, ok := units[c|0x20]
+		if !ok || digits == 0 {
+			return 0, fmt.Errorf("invalid TTL '%s'", s)
+		}
+		total += current * unit
+		current, digits = 0, 0
+	}
+	if digits != 0 {
+		return 0, fmt.Errorf("invalid TTL '%s'", s)
+	}
+	return total, nil
+}
+
+// Write serializes records as a zone file. The output is
+// deterministic: rrsets are sorted by name, the apex first, then by
+// type, and values are sorted within each rrset, so that the same set
+// of records always produces the same text.
+func Write(w io.Writer, origin string, records []livedns.DomainRecord) error {
+	zone := canonical(origin)
+	sorted := Sort(records)
+	bw := bufio.NewWriter(w)
+	fmt.Fprintf(bw, "$ORIGIN %s\n", zone)
+	for _, record := range sorted {
+		values := append([]string(nil), record.RrsetValues...)
+		sort.Strings(values)
+		for _, value := range values {
+			fmt.Fprintf(bw, "%s %d IN %s %s\n", record.RrsetName, record.RrsetTTL, strings.ToUpper(record.RrsetType), value)
+		}
+	}
+	return bw.Flush()
+}
+
+// Marshal returns the zone file produced by Write
+func Marshal(origin string, records []livedns.DomainRecord) ([]byte, error) {
+	var b strings.Builder
+	if err := Write(&b, origin, records); err != nil {
+		return nil, err
+	}
+	return []byte(b.String()), nil
+}
+
+var typeOrder = map[string]int{"SOA": 0, "NS": 1}
+
+// Sort returns a copy of the records in the order used by Write
+func Sort(records []livedns.DomainRecord) []livedns.DomainRecord {
+	sorted := append([]livedns.DomainRecord(nil), records...)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		a, b := sorted[i], sorted[j]
+		if a.RrsetName != b.RrsetName {
+			if a.RrsetName == Apex || b.RrsetName == Apex {
+				return a.RrsetName == Apex
+			}
+			return a.RrsetName < b.RrsetName
+		}
+		ta, tb := strings.ToUpper(a.RrsetType), strings.ToUpper(b.RrsetType)
+		oa, okA := typeOrder[ta]
+		ob, okB := typeOrder[tb]
+		switch {
+		case okA && okB:
+			return oa < ob
+		case okA != okB:
+			return okA
+		}
+		return ta < tb
+	})
+	return sorted
+}
+
+// Merge returns the records of a zone after the imported records have
+// been added to the current ones, as done by an import with
+// livedns.ImportMerge. Values are added to the existing rrsets, whose
+// TTL is replaced by the imported one.
+func Merge(current, imported []livedns.DomainRecord) []livedns.DomainRecord {
+	merged := make([]livedns.DomainRecord, 0, len(current)+len(imported))
+	index := map[[2]string]int{}
+	for _, records := range [][]livedns.DomainRecord{current, imported} {
+		for _, record := range records {
+			key := [2]string{record.RrsetName, strings.ToUpper(record.RrsetType)}
+			i, ok := index[key]
+			if !ok {
+				index[key] = len(merged)
+				record.RrsetValues = append([]string(nil), record.RrsetValues...)
+				merged = append(merged, record)
+				continue
+			}
+			merged[i].RrsetTTL = record.RrsetTTL
+		values:
+			for _, value := range record.RrsetValues {
+				for _, existing := range merged[i].RrsetValues {
+					if existing == value {
+						continue values
+					}
+				}
+				merged[i].RrsetValues = append(merged[i].RrsetValues, value)
+			}
+		}
+	}
+	return merged
+}
@@ -0,0 +1,116 @@
+package zonefile_test
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/go-gandi/go-gandi/livedns"
+	"github.com/go-gandi/go-gandi/livedns/zonefile"
+)
+
+const zone = `$ORIGIN example.com.
+$TTL 1h
+@	IN	SOA	ns1.gandi.net. hostmaster.gandi.net. (
+		2021010101 ; serial
+		10800      ; refresh
+		3600 604800 10800 )
+@		IN NS	ns1.gandi.net.
+		IN NS	ns2.gandi.net.
+www	300	IN A	192.0.2.1
+www.example.com.	IN	A	192.0.2.2
+txt		TXT	"v=spf1 include:_mailcust.gandi.net ?all" ; spf
+txt		TXT	"with; semicolon" "and \"quotes\""
+$ORIGIN sub.example.com.
+host	2d	A	192.0.2.3
+`
+
+func TestParse(t *testing.T) {
+	records, err := zonefile.Parse(strings.NewReader(zone), "example.com")
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := []livedns.DomainRecord{
+		{RrsetName: "@", RrsetType: "SOA", RrsetTTL: 3600, RrsetValues: []string{"ns1.gandi.net. hostmaster.gandi.net. 2021010101 10800 3600 604800 10800"}},
+		{RrsetName: "@", RrsetType: "NS", RrsetTTL: 3600, RrsetValues: []string{"ns1.gandi.net.", "ns2.gandi.net."}},
+		{RrsetName: "www", RrsetType: "A", RrsetTTL: 300, RrsetValues: []string{"192.0.2.1", "192.0.2.2"}},
+		{RrsetName: "txt", RrsetType: "TXT", RrsetTTL: 3600, RrsetValues: []string{`"v=spf1 include:_mailcust.gandi.net ?all"`, `"with; semicolon" "and \"quotes\""`}},
+		{RrsetName: "host.sub", RrsetType: "A", RrsetTTL: 172800, RrsetValues: []string{"192.0.2.3"}},
+	}
+	if !reflect.DeepEqual(records, expected) {
+		t.Fatalf("Records should be '%#v' (while they are %#v)", expected, records)
+	}
+}
+
+func TestParseOutOfZone(t *testing.T) {
+	_, err := zonefile.Parse(strings.NewReader("www.example.org. 300 IN A 192.0.2.1\n"), "example.com")
+	if err == nil {
+		t.Fatal("A name outside of the zone should be rejected")
+	}
+}
+
+func TestRoundTrip(t *testing.T) {
+	records, err := zonefile.Parse(strings.NewReader(zone), "example.com")
+	if err != nil {
+		t.Fatal(err)
+	}
+	first, err := zonefile.Marshal("example.com", records)
+	if err != nil {
+		t.Fatal(err)
+	}
+	reparsed, err := zonefile.Parse(strings.NewReader(string(first)), "example.com")
+	if err != nil {
+		t.Fatal(err)
+	}
+	second, err := zonefile.Marshal("example.com", reparsed)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(first) != string(second) {
+		t.Fatalf("Serialization is not stable:\n%s\n%s", first, second)
+	}
+	if !strings.HasPrefix(string(first), "$ORIGIN example.com.\n@ 3600 IN SOA ") {
+		t.Fatalf("Unexpected serialization:\n%s", first)
+	}
+}
+
+func TestParseTTL(t *testing.T) {
+	for input, expected := range map[string]int{"300": 300, "1h30m": 5400, "1W": 604800} {
+		ttl, err := zonefile.ParseTTL(input)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if ttl != expected {
+			t.Fatalf("TTL of '%s' should be %d (while it is %d)", input, expected, ttl)
+		}
+	}
+	if _, err := zonefile.ParseTTL("1x"); err == nil {
+		t.Fatal("An invalid TTL should be rejected")
+	}
+}
+
+func TestParseRelativeTargets(t *testing.T) {
+	records, err := zonefile.Parse(strings.NewReader(`$ORIGIN example.com.
+@	MX	10 mail
+www	CNAME	@
+$ORIGIN sub.example.com.
+host	CNAME	target
+@	NS	ns1
+_sip._tcp	SRV	0 5 5060 sip.example.net.
+svc	HTTPS	1 . alpn=h2
+`), "example.com")
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := []livedns.DomainRecord{
+		{RrsetName: "@", RrsetType: "MX", RrsetTTL: 10800, RrsetValues: []string{"10 mail.example.com."}},
+		{RrsetName: "www", RrsetType: "CNAME", RrsetTTL: 10800, RrsetValues: []string{"example.com."}},
+		{RrsetName: "host.sub", RrsetType: "CNAME", RrsetTTL: 10800, RrsetValues: []string{"target.sub.example.com."}},
+		{RrsetName: "sub", RrsetType: "NS", RrsetTTL: 10800, RrsetValues: []string{"ns1.sub.example.com."}},
+		{RrsetName: "_sip._tcp.sub", RrsetType: "SRV", RrsetTTL: 10800, RrsetValues: []string{"0 5 5060 sip.example.net."}},
+		{RrsetName: "svc.sub", RrsetType: "HTTPS", RrsetTTL: 10800, RrsetValues: []string{"1 . alpn=h2"}},
+	}
+	if !reflect.DeepEqual(records, expected) {
+		t.Fatalf("Records should be '%#v' (while they are %#v)", expected, records)
+	}
+}
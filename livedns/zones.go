@@ -0,0 +1,75 @@
+package livedns
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+)
+
+// ZoneFinder finds the LiveDNS domains hosting names. The domains
+// found are cached, so that the validations of several names of a
+// domain only look it up once.
+type ZoneFinder struct {
+	client *LiveDNS
+
+	mu    sync.Mutex
+	zones map[string]bool
+}
+
+// NewZoneFinder returns a ZoneFinder using a LiveDNS client
+func NewZoneFinder(client *LiveDNS) *ZoneFinder {
+	return &ZoneFinder{client: client, zones: map[string]bool{}}
+}
+
+// FindZone returns the LiveDNS domain hosting a name, and the name of
+// the record relative to this domain. Parent domains are tried from
+// the longest to the shortest until one is found in LiveDNS.
+func (g *LiveDNS) FindZone(fqdn string) (zone, name string, err error) {
+	return NewZoneFinder(g).FindZone(fqdn)
+}
+
+// FindZone returns the LiveDNS domain hosting a name, and the name of
+// the record relative to this domain, as LiveDNS.FindZone does
+func (f *ZoneFinder) FindZone(fqdn string) (zone, name string, err error) {
+	fqdn = strings.ToLower(strings.TrimSuffix(fqdn, "."))
+	labels := strings.Split(fqdn, ".")
+	for i := 0; i < len(labels)-1; i++ {
+		candidate := strings.Join(labels[i:], ".")
+		found, err := f.isZone(candidate)
+		if err != nil {
+			return "", "", err
+		}
+		if found {
+			name = strings.Join(labels[:i], ".")
+			if name == "" {
+				name = "@"
+			}
+			return candidate, name, nil
+		}
+	}
+	return "", "", fmt.Errorf("No LiveDNS domain found for '%s'", fqdn)
+}
+
+// isZone tells if a name is a LiveDNS domain. Only a 404 means that it
+// is not: the other errors, such as a 403 for a token without access
+// to the domain, are returned.
+func (f *ZoneFinder) isZone(candidate string) (bool, error) {
+	f.mu.Lock()
+	_, ok := f.zones[candidate]
+	f.mu.Unlock()
+	if ok {
+		return true, nil
+	}
+	_, err := f.client.GetDomain(candidate)
+	if isStatus(err, http.StatusNotFound) {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("Fail to look up the domain '%s' (error '%w')", candidate, err)
+	}
+	f.mu.Lock()
+	f.zones[candidate] = true
+	f.mu.Unlock()
+	return true, nil
+}
@@ -0,0 +1,61 @@
+package livedns_test
+
+import (
+	"testing"
+
+	"github.com/go-gandi/go-gandi/config"
+	"github.com/go-gandi/go-gandi/livedns"
+	"gopkg.in/h2non/gock.v1"
+)
+
+func TestZoneFinder(t *testing.T) {
+	defer gock.Off()
+	gock.New("https://api.gandi.net/v5/").
+		Get("livedns/domains/_dnsauth.www.example.com$").
+		Reply(404).
+		JSON(map[string]interface{}{"code": 404, "message": "Unknown domain"})
+	gock.New("https://api.gandi.net/v5/").
+		Get("livedns/domains/www.example.com$").
+		Reply(404).
+		JSON(map[string]interface{}{"code": 404, "message": "Unknown domain"})
+	gock.New("https://api.gandi.net/v5/").
+		Get("livedns/domains/example.com$").
+		Reply(200).
+		JSON(livedns.Domain{FQDN: "example.com"})
+
+	finder := livedns.NewZoneFinder(livedns.New(config.Config{}))
+	zone, name, err := finder.FindZone("_dnsauth.www.example.com.")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if zone != "example.com" || name != "_dnsauth.www" {
+		t.Fatalf("Unexpected zone '%s' and name '%s'", zone, name)
+	}
+	// The domain found is cached
+	zone, name, err = finder.FindZone("Example.com")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if zone != "example.com" || name != "@" {
+		t.Fatalf("Unexpected zone '%s' and name '%s'", zone, name)
+	}
+	if !gock.IsDone() {
+		t.Fatal("All the expected requests have not been sent")
+	}
+}
+
+func TestFindZoneForbidden(t *testing.T) {
+	defer gock.Off()
+	gock.New("https://api.gandi.net/v5/").
+		Get("livedns/domains/www.example.com$").
+		Reply(403).
+		JSON(map[string]interface{}{"code": 403, "message": "Access was denied to this resource"})
+
+	// A permission error is not mistaken for a missing zone
+	if _, _, err := livedns.New(config.Config{}).FindZone("www.example.com"); err == nil {
+		t.Fatal("The permission error should be returned")
+	}
+	if !gock.IsDone() {
+		t.Fatal("All the expected requests have not been sent")
+	}
+}